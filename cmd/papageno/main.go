@@ -0,0 +1,285 @@
+/*
+Command papageno generates parallel operator precedence parsers from a lexer
+and a grammar specification.
+
+Usage:
+
+	papageno <command> [flags]
+
+The commands are:
+
+	generate  generate the parser files in an output directory
+	check     validate the specifications without writing any file
+	explain   print the terminals, nonterminals, rewritten rules and precedence matrix
+
+It can be invoked from go:generate, for example:
+
+	//go:generate papageno generate -lexer lexer/xml.l -grammar parser/xml.g -out .
+
+The -lexer flag may be omitted to generate only the parser, whose ParseTokens method
+takes the symbols produced by a hand-written lexer.
+
+The -prefix flag allows to generate several parsers in the same package:
+with -prefix expr, for example, Parser becomes ExprParser, NewParser becomes
+ExprNewParser and tokens.go becomes expr_tokens.go.
+
+With the -verify flag, generate does not write any file: it prints a unified diff
+of each generated file that differs from the one in the output directory and fails
+if there is at least one, so that stale parsers can be detected before merging.
+
+By default only the warnings about the specifications are printed, on the standard error,
+such as the ones about the lexer rules that can never match because an earlier rule
+matches the same strings. With the -werror flag, generate and check fail if there are any.
+The -v flag also prints the progress of the generation, while -debug prints every
+intermediate stage of the analysis, such as the rewritten rules and the precedence matrix.
+
+The exit status is 0 on success, 1 if the specifications are invalid or the files
+cannot be written, and 2 if the command line is invalid.
+*/
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/simoneguidi94/gopapageno/generator"
+)
+
+const (
+	exitSuccess = 0
+	exitFailure = 1
+	exitUsage   = 2
+)
+
+/*
+command describes a subcommand of papageno.
+*/
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) int
+}
+
+var commands = []command{
+	{"generate", "generate the parser files in an output directory", runGenerate},
+	{"check", "validate the specifications without writing any file", runCheck},
+	{"explain", "print the terminals, nonterminals, rewritten rules and precedence matrix", runExplain},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	if len(args) == 0 {
+		usage(os.Stderr)
+		return exitUsage
+	}
+
+	name := args[0]
+
+	if name == "help" || name == "-h" || name == "-help" || name == "--help" {
+		usage(os.Stdout)
+		return exitSuccess
+	}
+
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd.run(args[1:])
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "papageno: unknown command %q\n", name)
+	usage(os.Stderr)
+	return exitUsage
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: papageno <command> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-9s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run 'papageno <command> -h' for the flags of a command.")
+}
+
+/*
+specFlags contains the flags shared by all the commands.
+*/
+type specFlags struct {
+	lexer   string
+	grammar string
+	verbose bool
+	debug   bool
+}
+
+/*
+logger returns the logger selected by the flags: by default only the warnings are logged,
+-v adds the progress of the generation and -debug adds every intermediate stage.
+*/
+func (spec *specFlags) logger() *slog.Logger {
+	level := slog.LevelWarn
+	if spec.debug {
+		level = slog.LevelDebug
+	} else if spec.verbose {
+		level = slog.LevelInfo
+	}
+
+	return slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
+		Level: level,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey && len(groups) == 0 {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+}
+
+/*
+options returns the generator options for the specifications and the logger selected by the flags.
+*/
+func (spec *specFlags) options() generator.Options {
+	return generator.Options{
+		LexerFile:   spec.lexer,
+		GrammarFile: spec.grammar,
+		Logger:      spec.logger(),
+	}
+}
+
+func newFlagSet(name string, synopsis string, spec *specFlags) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.StringVar(&spec.lexer, "lexer", "", "the lexer specification (.l) `file`")
+	fs.StringVar(&spec.grammar, "grammar", "", "the grammar specification (.g) `file`")
+	fs.BoolVar(&spec.verbose, "v", false, "log the progress of the generation")
+	fs.BoolVar(&spec.debug, "debug", false, "log every intermediate stage of the analysis of the specifications")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: papageno %s %s\n\n", name, synopsis)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+/*
+parseFlags parses the arguments of a command and checks that the specifications were provided.
+It returns false together with the exit code if the command must not be run.
+*/
+func parseFlags(fs *flag.FlagSet, args []string, spec *specFlags) (bool, int) {
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return false, exitSuccess
+		}
+		return false, exitUsage
+	}
+
+	if fs.NArg() > 0 {
+		fmt.Fprintf(fs.Output(), "papageno %s: unexpected arguments: %v\n", fs.Name(), fs.Args())
+		fs.Usage()
+		return false, exitUsage
+	}
+
+	if spec.grammar == "" {
+		fmt.Fprintf(fs.Output(), "papageno %s: -grammar is required\n", fs.Name())
+		fs.Usage()
+		return false, exitUsage
+	}
+
+	return true, exitSuccess
+}
+
+func fail(name string, err error) int {
+	fmt.Fprintf(os.Stderr, "papageno %s: %s\n", name, err.Error())
+	return exitFailure
+}
+
+func runGenerate(args []string) int {
+	var spec specFlags
+	fs := newFlagSet("generate", "[-lexer file] -grammar file [-out dir] [-pkg name] [-prefix name] [-verify] [-werror] [-v] [-debug]", &spec)
+	outdir := fs.String("out", ".", "the output `directory`")
+	packageName := fs.String("pkg", os.Getenv("GOPACKAGE"), "the `name` of the generated package (default: $GOPACKAGE or the name of the output directory)")
+	prefix := fs.String("prefix", "", "a `name` added to every identifier and file name of the parser, so that several parsers can share a package")
+	verify := fs.Bool("verify", false, "do not write any file, print the differences between the generated files and the ones in the output directory and fail if there are any")
+	werror := fs.Bool("werror", false, "treat the warnings about the specifications as errors")
+
+	if ok, code := parseFlags(fs, args, &spec); !ok {
+		return code
+	}
+
+	opts := spec.options()
+	opts.OutDir = *outdir
+	opts.PackageName = *packageName
+	opts.Prefix = *prefix
+	opts.WarningsAsErrors = *werror
+
+	if *verify {
+		return runVerify(opts)
+	}
+
+	if _, err := generator.GenerateWithOptions(opts); err != nil {
+		return fail("generate", err)
+	}
+
+	return exitSuccess
+}
+
+/*
+runVerify prints the differences between the generated parser and the one in the output directory.
+It fails if the parser in the output directory is out of date.
+*/
+func runVerify(opts generator.Options) int {
+	staleFiles, err := generator.Verify(opts)
+
+	if err != nil {
+		return fail("generate", err)
+	}
+
+	for _, staleFile := range staleFiles {
+		fmt.Print(staleFile.Diff)
+	}
+
+	if len(staleFiles) > 0 {
+		fmt.Fprintf(os.Stderr, "papageno generate: %d generated file(s) in %s are out of date, regenerate them from %s and %s\n", len(staleFiles), opts.OutDir, opts.LexerFile, opts.GrammarFile)
+		return exitFailure
+	}
+
+	return exitSuccess
+}
+
+func runCheck(args []string) int {
+	var spec specFlags
+	fs := newFlagSet("check", "[-lexer file] -grammar file [-werror] [-v] [-debug]", &spec)
+	werror := fs.Bool("werror", false, "treat the warnings about the specifications as errors")
+
+	if ok, code := parseFlags(fs, args, &spec); !ok {
+		return code
+	}
+
+	opts := spec.options()
+	opts.WarningsAsErrors = *werror
+
+	if _, err := generator.CheckWithOptions(opts); err != nil {
+		return fail("check", err)
+	}
+
+	return exitSuccess
+}
+
+func runExplain(args []string) int {
+	var spec specFlags
+	fs := newFlagSet("explain", "[-lexer file] -grammar file [-v] [-debug]", &spec)
+
+	if ok, code := parseFlags(fs, args, &spec); !ok {
+		return code
+	}
+
+	if err := generator.ExplainWithOptions(spec.options(), os.Stdout); err != nil {
+		return fail("explain", err)
+	}
+
+	return exitSuccess
+}
@@ -1,7 +0,0 @@
-type lexerDfaState struct {
-	Transitions     [256]int
-	IsFinal         bool
-	AssociatedRules []int
-}
-
-type lexerDfa []lexerDfaState
\ No newline at end of file
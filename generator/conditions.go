@@ -0,0 +1,148 @@
+package generator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+/*
+initialCondition is the start condition that is active when the lexing begins.
+*/
+const initialCondition = "INITIAL"
+
+/*
+lexCondition is a start condition of the lexer, declared with %s if it is inclusive
+or with %x if it is exclusive. The rules without a condition prefix are active
+in the inclusive conditions only. Pos is the position of the declaration.
+*/
+type lexCondition struct {
+	Name      string
+	Exclusive bool
+	Pos       position
+}
+
+var conditionPrefixRegex = regexp.MustCompile(`^<\s*(\*|[a-zA-Z_][a-zA-Z0-9_]*(\s*,\s*[a-zA-Z_][a-zA-Z0-9_]*)*)\s*>`)
+
+var beginRegex = regexp.MustCompile(`\bBEGIN\s*\(\s*([a-zA-Z_][a-zA-Z0-9_]*)\s*\)`)
+
+/*
+conditionIndex returns the position of the condition with the given name, or -1 if it is not declared.
+*/
+func conditionIndex(conditions []lexCondition, name string) int {
+	for i, c := range conditions {
+		if c.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+/*
+parseConditionPrefix reads the prefix of a lexer rule that lists its start conditions, such as <COMMENT,STRING> or <*>.
+It returns the conditions and the length of the prefix, or nil and 0 if the rule has no prefix.
+Since a regular expression may start with <, a prefix is recognized only if it is <*> or <INITIAL>,
+or if the specification declares some start conditions.
+*/
+func parseConditionPrefix(bytes []byte, conditions []lexCondition) ([]string, int) {
+	match := conditionPrefixRegex.FindSubmatch(bytes)
+
+	if match == nil {
+		return nil, 0
+	}
+
+	names := strings.Split(string(match[1]), ",")
+	for i := range names {
+		names[i] = strings.TrimSpace(names[i])
+	}
+
+	if len(conditions) == 1 && names[0] != "*" && !(len(names) == 1 && names[0] == initialCondition) {
+		return nil, 0
+	}
+
+	return names, len(match[0])
+}
+
+/*
+isActive returns true if a lexer rule with the given condition prefix is active in the condition c.
+*/
+func isActive(ruleConditions []string, c lexCondition) bool {
+	if ruleConditions == nil {
+		return !c.Exclusive
+	}
+	for _, name := range ruleConditions {
+		if name == "*" || name == c.Name {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+beginTargets returns the names of the conditions activated by the BEGIN calls of a lexer action,
+in order of appearance and without repetitions.
+*/
+func beginTargets(action string) []string {
+	targets := make([]string, 0)
+	for _, match := range beginRegex.FindAllStringSubmatch(action, -1) {
+		found := false
+		for _, t := range targets {
+			if t == match[1] {
+				found = true
+				break
+			}
+		}
+		if !found {
+			targets = append(targets, match[1])
+		}
+	}
+	return targets
+}
+
+/*
+rewriteBegin replaces each BEGIN(NAME) of a lexer action with the assignment
+of the number of the condition to the condition argument of the lexer function.
+The conditions must have been validated by checkConditions.
+*/
+func rewriteBegin(action string, conditions []lexCondition) string {
+	return beginRegex.ReplaceAllStringFunc(action, func(call string) string {
+		name := beginRegex.FindStringSubmatch(call)[1]
+		return fmt.Sprintf("*condition = %d /* %s */", conditionIndex(conditions, name), name)
+	})
+}
+
+/*
+checkConditions checks that the condition prefixes and the BEGIN calls of the lexer rules
+only name declared conditions, and that each condition has at least one active rule.
+*/
+func checkConditions(filename string, lexRules []lexRule, conditions []lexCondition) error {
+	errs := make(ErrorList, 0)
+
+	for i, r := range lexRules {
+		for _, name := range r.Conditions {
+			if name != "*" && conditionIndex(conditions, name) < 0 {
+				errs = append(errs, newSyntaxError(filename, r.Pos, &StartConditionError{name, fmt.Sprintf("used by lexer rule %d, is not declared", i)}))
+			}
+		}
+		for _, name := range beginTargets(r.Action) {
+			if conditionIndex(conditions, name) < 0 {
+				errs = append(errs, newSyntaxError(filename, r.Pos, &StartConditionError{name, fmt.Sprintf("activated by lexer rule %d, is not declared", i)}))
+			}
+		}
+	}
+
+	for _, c := range conditions {
+		hasRules := false
+		for _, r := range lexRules {
+			if isActive(r.Conditions, c) {
+				hasRules = true
+				break
+			}
+		}
+		if !hasRules {
+			errs = append(errs, newSyntaxError(filename, c.Pos, &StartConditionError{c.Name, "has no rules"}))
+		}
+	}
+
+	return errs.Err()
+}
@@ -0,0 +1,100 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+/*
+Warning is a problem found in a specification that does not prevent the generation of the parser.
+Line and Column locate the problem in the file, and are 0 if it concerns the whole file.
+*/
+type Warning struct {
+	Filename string
+	Line     int
+	Column   int
+	Msg      string
+}
+
+func (w Warning) String() string {
+	if w.Line == 0 {
+		return fmt.Sprintf("%s: %s", w.Filename, w.Msg)
+	}
+	return fmt.Sprintf("%s:%d:%d: %s", w.Filename, w.Line, w.Column, w.Msg)
+}
+
+/*
+diagnostics collects the warnings found while analyzing the specifications
+and logs the intermediate stages of the analysis.
+*/
+type diagnostics struct {
+	logger   *slog.Logger
+	warnings []Warning
+}
+
+/*
+newDiagnostics creates a diagnostics that logs to logger, or that is quiet if logger is nil.
+*/
+func newDiagnostics(logger *slog.Logger) *diagnostics {
+	if logger == nil {
+		logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	}
+	return &diagnostics{logger, make([]Warning, 0)}
+}
+
+/*
+Warn records a warning about a specification and logs it.
+*/
+func (d *diagnostics) Warn(filename string, msg string) {
+	d.WarnAt(filename, position{}, msg)
+}
+
+/*
+WarnAt records a warning about a position of a specification and logs it.
+*/
+func (d *diagnostics) WarnAt(filename string, pos position, msg string) {
+	w := Warning{filename, pos.Line, pos.Column, msg}
+	d.warnings = append(d.warnings, w)
+	if pos.Line == 0 {
+		d.logger.Warn(msg, "file", filename)
+	} else {
+		d.logger.Warn(msg, "file", fmt.Sprintf("%s:%d:%d", filename, pos.Line, pos.Column))
+	}
+}
+
+/*
+Dump logs at the debug level the description of an intermediate stage of the analysis,
+with a record for each line of the description.
+*/
+func (d *diagnostics) Dump(stage string, description fmt.Stringer) {
+	if !d.logger.Enabled(context.Background(), slog.LevelDebug) {
+		return
+	}
+
+	for _, line := range strings.Split(description.String(), "\n") {
+		d.logger.Debug(stage, "line", line)
+	}
+}
+
+/*
+lines is a list of lines that can be dumped, one for each element.
+*/
+type lines []string
+
+func (l lines) String() string {
+	return strings.Join(l, "\n")
+}
+
+/*
+ruleLines returns the rules as a list of lines.
+*/
+func ruleLines(rules []rule) lines {
+	l := make(lines, len(rules))
+	for i, r := range rules {
+		l[i] = r.String()
+	}
+	return l
+}
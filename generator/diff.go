@@ -0,0 +1,209 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+)
+
+/*
+diffOp is a line of an edit script: Kind is ' ' for a line common to both texts,
+'-' for a line that only appears in the old text and '+' for a line that only appears in the new one.
+*/
+type diffOp struct {
+	Kind byte
+	Line string
+}
+
+/*
+diffLines computes the shortest edit script that transforms a into b
+using the algorithm by Eugene W. Myers.
+*/
+func diffLines(a []string, b []string) []diffOp {
+	n := len(a)
+	m := len(b)
+	maxD := n + m
+	offset := maxD + 1
+
+	v := make([]int, 2*maxD+3)
+	trace := make([][]int, 0)
+
+	found := false
+	for d := 0; d <= maxD && !found; d++ {
+		//Save a copy of v before updating it, it is needed to backtrack the edit script
+		vCopy := make([]int, len(v))
+		copy(vCopy, v)
+		trace = append(trace, vCopy)
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				found = true
+				break
+			}
+		}
+	}
+
+	//Backtrack from the end of both texts to build the edit script in reverse order
+	ops := make([]diffOp, 0, n+m)
+	x := n
+	y := m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			ops = append(ops, diffOp{' ', a[x]})
+		}
+
+		if d > 0 {
+			if x == prevX {
+				y--
+				ops = append(ops, diffOp{'+', b[y]})
+			} else {
+				x--
+				ops = append(ops, diffOp{'-', a[x]})
+			}
+		}
+	}
+
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+
+	return ops
+}
+
+/*
+splitLines splits a text into lines, keeping the line terminators.
+*/
+func splitLines(text []byte) []string {
+	lines := strings.SplitAfter(string(text), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+/*
+unifiedDiff returns the differences between two texts in the unified format,
+with three lines of context around each change. It returns an empty string if the texts are equal.
+*/
+func unifiedDiff(oldName string, newName string, oldText []byte, newText []byte) string {
+	const context = 3
+
+	ops := diffLines(splitLines(oldText), splitLines(newText))
+
+	var sb strings.Builder
+
+	i := 0
+	oldLine := 1
+	newLine := 1
+	for i < len(ops) {
+		//Skip the common lines that are not close to a change
+		if ops[i].Kind == ' ' {
+			i++
+			oldLine++
+			newLine++
+			continue
+		}
+
+		if sb.Len() == 0 {
+			fmt.Fprintf(&sb, "--- %s\n+++ %s\n", oldName, newName)
+		}
+
+		//Find the end of the hunk: it ends when more than 2*context common lines follow a change
+		start := i - context
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		for end < len(ops) {
+			if ops[end].Kind != ' ' {
+				end++
+				continue
+			}
+			common := 0
+			for end+common < len(ops) && ops[end+common].Kind == ' ' {
+				common++
+			}
+			if end+common == len(ops) || common > 2*context {
+				if common > context {
+					common = context
+				}
+				end += common
+				break
+			}
+			end += common
+		}
+
+		hunkOldStart := oldLine - (i - start)
+		hunkNewStart := newLine - (i - start)
+		hunkOldLen := 0
+		hunkNewLen := 0
+		for _, op := range ops[start:end] {
+			if op.Kind != '+' {
+				hunkOldLen++
+			}
+			if op.Kind != '-' {
+				hunkNewLen++
+			}
+		}
+
+		fmt.Fprintf(&sb, "@@ -%s +%s @@\n", hunkRange(hunkOldStart, hunkOldLen), hunkRange(hunkNewStart, hunkNewLen))
+		for _, op := range ops[start:end] {
+			sb.WriteByte(op.Kind)
+			sb.WriteString(op.Line)
+			if !strings.HasSuffix(op.Line, "\n") {
+				sb.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+
+		for _, op := range ops[i:end] {
+			if op.Kind != '+' {
+				oldLine++
+			}
+			if op.Kind != '-' {
+				newLine++
+			}
+		}
+		i = end
+	}
+
+	return sb.String()
+}
+
+/*
+hunkRange formats the range of lines of a hunk.
+An empty range starts at the line before the change, as in GNU diff.
+*/
+func hunkRange(start int, length int) string {
+	if length == 0 {
+		return fmt.Sprintf("%d,0", start-1)
+	}
+	if length == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, length)
+}
@@ -1,122 +1,138 @@
 package generator
 
 import (
+	"bytes"
 	"fmt"
-	"io/ioutil"
+	"go/format"
+	"log/slog"
 	"math"
 	"os"
-	"path"
-	"sort"
+	"path/filepath"
 	"strings"
 
 	"github.com/simoneguidi94/gopapageno/generator/regex"
+	"github.com/simoneguidi94/gopapageno/papageno"
 )
 
-func emitOutputFolder(outdir string) error {
+func emitOutputFolder(outdir string, logger *slog.Logger) error {
 	_, err := os.Stat(outdir)
 
 	if err != nil {
-		err = os.Mkdir(outdir, os.ModeDir)
+		err = os.MkdirAll(outdir, 0755)
 		if err != nil {
 			return err
 		} else {
-			fmt.Println("Created directory " + outdir)
+			logger.Info("created directory", "dir", outdir)
 		}
 	}
 
 	return nil
 }
 
-func emitLexerAutomata(outdir string, dfa regex.Dfa, cutPointsDfa regex.Dfa) error {
-	outPath := outdir + "/" + "lexerautomata.go"
-	file, err := createFile(outPath)
+func emitLexerAutomata(packageName string, dfas []regex.Dfa, conditions []lexCondition, cutPointsDfa regex.Dfa) GeneratedFile {
+	var file bytes.Buffer
 
-	if err != nil {
-		return err
-	}
+	file.WriteString(fmt.Sprintf("package %s\n\n", packageName))
+	file.WriteString(fmt.Sprintf("import \"%s\"\n\n", runtimeImportPath))
 
-	defer file.Close()
+	file.WriteString("/*\n")
+	file.WriteString("The automata of the lexer, one for each start condition.\n")
+	file.WriteString("*/\n")
+	file.WriteString("var lexerAutomata = []papageno.LexerDfa{\n")
+	for c, dfa := range dfas {
+		file.WriteString(fmt.Sprintf("\t//%s\n", conditions[c].Name))
+		file.WriteString("\t")
+		writeLexerDfa(&file, lexerDfa(dfa), "\t")
+		file.WriteString(",\n")
+	}
+	file.WriteString("}\n\n")
 
-	packageName := path.Base(outdir)
+	file.WriteString("var cutPointsAutomaton = papageno.LexerDfa")
+	writeLexerDfa(&file, lexerDfa(cutPointsDfa), "")
 
-	file.WriteString(fmt.Sprintf("package %s\n\n", packageName))
+	return GeneratedFile{"lexerautomata.go", file.Bytes()}
+}
 
-	file.WriteString("var lexerAutomaton lexerDfa = []lexerDfaState {\n")
-	states := dfa.GetStates()
-	for _, state := range states {
-		file.WriteString("\tlexerDfaState{[256]int{")
-		for i := 0; i < len(state.Transitions)-1; i++ {
-			if state.Transitions[i] == nil {
-				file.WriteString("-1, ")
-			} else {
-				file.WriteString(fmt.Sprintf("%d, ", state.Transitions[i].Num))
-			}
-		}
-		if state.Transitions[len(state.Transitions)-1] == nil {
-			file.WriteString("-1")
-		} else {
-			file.WriteString(fmt.Sprintf("%d", state.Transitions[len(state.Transitions)-1].Num))
-		}
-		file.WriteString(fmt.Sprintf("}, %t, []int{", state.IsFinal))
-		sort.Ints(state.AssociatedRules)
-		for i := 0; i < len(state.AssociatedRules)-1; i++ {
-			file.WriteString(fmt.Sprintf("%d, ", state.AssociatedRules[i]))
-		}
-		if len(state.AssociatedRules) > 0 {
-			file.WriteString(fmt.Sprintf("%d", state.AssociatedRules[len(state.AssociatedRules)-1]))
-		}
-		file.WriteString("}},\n")
+/*
+writeLexerDfa writes the literal of an automaton, indenting its fields with indent plus a tab.
+The transitions are written as a row for each state.
+*/
+func writeLexerDfa(file *bytes.Buffer, automaton papageno.LexerDfa, indent string) {
+	classes := make([]int, len(automaton.Classes))
+	for i, class := range automaton.Classes {
+		classes[i] = int(class)
 	}
-	file.WriteString("}\n\n")
 
-	file.WriteString("var cutPointsAutomaton lexerDfa = []lexerDfaState {\n")
-	states = cutPointsDfa.GetStates()
-	for _, state := range states {
-		file.WriteString("\tlexerDfaState{[256]int{")
-		for i := 0; i < len(state.Transitions)-1; i++ {
-			if state.Transitions[i] == nil {
-				file.WriteString("-1, ")
+	file.WriteString("{\n")
+	file.WriteString(fmt.Sprintf("%s\tClasses: [256]uint8{%s},\n", indent, intsToString(classes)))
+	file.WriteString(fmt.Sprintf("%s\tNumClasses: %d,\n", indent, automaton.NumClasses))
+	file.WriteString(fmt.Sprintf("%s\tTransitions: []uint16{\n", indent))
+	for i := range automaton.IsFinal {
+		row := automaton.Transitions[i*automaton.NumClasses : (i+1)*automaton.NumClasses]
+		strs := make([]string, len(row))
+		for j, next := range row {
+			if next == papageno.LexerNoTransition {
+				strs[j] = "papageno.LexerNoTransition"
 			} else {
-				file.WriteString(fmt.Sprintf("%d, ", state.Transitions[i].Num))
+				strs[j] = fmt.Sprintf("%d", next)
 			}
 		}
-		if state.Transitions[len(state.Transitions)-1] == nil {
-			file.WriteString("-1")
-		} else {
-			file.WriteString(fmt.Sprintf("%d", state.Transitions[len(state.Transitions)-1].Num))
+		file.WriteString(fmt.Sprintf("%s\t\t%s,\n", indent, strings.Join(strs, ", ")))
+	}
+	file.WriteString(fmt.Sprintf("%s\t},\n", indent))
+	file.WriteString(fmt.Sprintf("%s\tIsFinal: []bool{", indent))
+	for i, isFinal := range automaton.IsFinal {
+		if i > 0 {
+			file.WriteString(", ")
 		}
-		file.WriteString(fmt.Sprintf("}, %t, []int{}},\n", state.IsFinal))
+		file.WriteString(fmt.Sprintf("%t", isFinal))
 	}
-	file.WriteString("}")
-
-	return nil
+	file.WriteString("},\n")
+	file.WriteString(fmt.Sprintf("%s\tAssociatedRules: [][]int{\n", indent))
+	for _, rules := range automaton.AssociatedRules {
+		file.WriteString(fmt.Sprintf("%s\t\t{%s},\n", indent, intsToString(rules)))
+	}
+	file.WriteString(fmt.Sprintf("%s\t},\n", indent))
+	file.WriteString(fmt.Sprintf("%s}", indent))
 }
 
-func emitLexerFunction(outdir string, lexCode string, lexRules []lexRule) error {
-	outPath := outdir + "/" + "lexerfunction.go"
-	file, err := createFile(outPath)
-
-	if err != nil {
-		return err
+/*
+intsToString returns the integers separated by commas.
+*/
+func intsToString(ints []int) string {
+	strs := make([]string, len(ints))
+	for i, n := range ints {
+		strs[i] = fmt.Sprintf("%d", n)
 	}
+	return strings.Join(strs, ", ")
+}
 
-	defer file.Close()
-
-	packageName := path.Base(outdir)
+func emitLexerFunction(packageName string, lexCode string, lexRules []lexRule, conditions []lexCondition) GeneratedFile {
+	var file bytes.Buffer
 
 	file.WriteString(fmt.Sprintf("package %s\n\n", packageName))
 
 	file.WriteString(lexCode)
 	file.WriteString("\n\n")
 
+	if !declares(lexCode, "lexerContext") {
+		file.WriteString("/*\n")
+		file.WriteString("lexerContext contains the state of the semantic function of the lexer owned by each Parser,\n")
+		file.WriteString("which the specification of the lexer did not declare.\n")
+		file.WriteString("*/\n")
+		file.WriteString("type lexerContext struct{}\n\n")
+	}
+
 	file.WriteString("/*\n")
 	file.WriteString("lexerFunction is the semantic function of the lexer.\n")
+	file.WriteString("ctx is the lexerContext of the Parser, initialized by lexerPreallocMem before each parse.\n")
+	file.WriteString("BEGIN(NAME) in the actions is replaced with the assignment of the start condition NAME to condition.\n")
 	file.WriteString("*/\n")
-	file.WriteString("func lexerFunction(thread int, ruleNum int, yytext string, genSym *symbol) int {\n")
+	file.WriteString("func lexerFunction(ctx *lexerContext, thread int, ruleNum int, yytext string, genSym *Symbol, condition *int) int {\n")
 	file.WriteString("\tswitch ruleNum {\n")
 	for i, rule := range lexRules {
 		file.WriteString(fmt.Sprintf("\tcase %d:\n", i))
-		action := rule.Action
+		action := rewriteBegin(rule.Action, conditions)
 		lines := strings.Split(action, "\n")
 		for _, line := range lines {
 			file.WriteString("\t\t")
@@ -128,22 +144,14 @@ func emitLexerFunction(outdir string, lexCode string, lexRules []lexRule) error
 	file.WriteString("\treturn _ERROR\n")
 	file.WriteString("}\n")
 
-	return nil
+	return GeneratedFile{"lexerfunction.go", file.Bytes()}
 }
 
-func emitTokens(outdir string, nonterminals stringSet, terminals stringSet) error {
-	outPath := outdir + "/" + "tokens.go"
-	file, err := createFile(outPath)
-
-	if err != nil {
-		return err
-	}
-
-	defer file.Close()
-
-	packageName := path.Base(outdir)
+func emitTokens(packageName string, nonterminals stringSet, terminals stringSet) GeneratedFile {
+	var file bytes.Buffer
 
 	file.WriteString(fmt.Sprintf("package %s\n\n", packageName))
+	file.WriteString(fmt.Sprintf("import \"%s\"\n\n", runtimeImportPath))
 	file.WriteString(fmt.Sprintf("const _NUM_NONTERMINALS = %d\n", len(nonterminals)))
 	file.WriteString(fmt.Sprintf("const _NUM_TERMINALS = %d\n\n", len(terminals)))
 
@@ -156,15 +164,31 @@ func emitTokens(outdir string, nonterminals stringSet, terminals stringSet) erro
 	}
 	file.WriteString(")\n\n")
 
-	file.WriteString("func tokenValue(token uint16) uint16 {\n")
-	file.WriteString("\treturn 0x7FFF & token\n")
-	file.WriteString("}\n\n")
+	file.WriteString("/*\n")
+	file.WriteString("The exported names of the tokens, to be compared with the Token field of a Symbol.\n")
+	file.WriteString("*/\n")
+	file.WriteString("const (\n")
+	for _, token := range nonterminals {
+		if !strings.HasPrefix(token, "_") {
+			file.WriteString(fmt.Sprintf("\t%s Token = %s\n", exportedTokenName(token), token))
+		}
+	}
+	for _, token := range terminals {
+		if !strings.HasPrefix(token, "_") {
+			file.WriteString(fmt.Sprintf("\t%s Token = %s\n", exportedTokenName(token), token))
+		}
+	}
+	file.WriteString(")\n\n")
 
-	file.WriteString("func isTerminal(token uint16) bool {\n")
-	file.WriteString("\treturn token >= 0x800\n")
+	file.WriteString("/*\n")
+	file.WriteString("TokenString returns the name of a token as written in the specifications,\n")
+	file.WriteString("or _ERROR for the error nodes that replace the symbols that could not be parsed.\n")
+	file.WriteString("*/\n")
+	file.WriteString("func TokenString(token Token) string {\n")
+	file.WriteString("\treturn tokenToString(token)\n")
 	file.WriteString("}\n\n")
 
-	file.WriteString("func tokenToString(token uint16) string {\n")
+	file.WriteString("func tokenToString(token Token) string {\n")
 	file.WriteString("\tswitch token {\n")
 	for _, token := range nonterminals {
 		file.WriteString(fmt.Sprintf("\tcase %s:\n", token))
@@ -174,22 +198,25 @@ func emitTokens(outdir string, nonterminals stringSet, terminals stringSet) erro
 		file.WriteString(fmt.Sprintf("\tcase %s:\n", token))
 		file.WriteString(fmt.Sprintf("\t\treturn \"%s\"\n", token))
 	}
+	file.WriteString("\tcase papageno.ErrorToken:\n")
+	file.WriteString("\t\treturn \"_ERROR\"\n")
 	file.WriteString("\t}\n")
 	file.WriteString("\treturn \"UNKNOWN_TOKEN\"\n")
 	file.WriteString("}")
 
-	return nil
+	return GeneratedFile{"tokens.go", file.Bytes()}
 }
 
-func emitRules(outdir string, rules []rule, nonterminals stringSet, terminals stringSet) error {
-	outPath := outdir + "/" + "rules.go"
-	file, err := createFile(outPath)
-
-	if err != nil {
-		return err
-	}
+/*
+exportedTokenName returns the exported name of a token, made of the prefix Token
+followed by the name of the token with its first letter in upper case.
+*/
+func exportedTokenName(token string) string {
+	return "Token" + strings.ToUpper(token[:1]) + token[1:]
+}
 
-	defer file.Close()
+func emitRules(packageName string, rules []rule, nonterminals stringSet, terminals stringSet, syncTerminals stringSet) GeneratedFile {
+	var file bytes.Buffer
 
 	maxRHSLen := 0
 
@@ -199,35 +226,19 @@ func emitRules(outdir string, rules []rule, nonterminals stringSet, terminals st
 		}
 	}
 
-	packageName := path.Base(outdir)
-
 	file.WriteString(fmt.Sprintf("package %s\n\n", packageName))
 
-	file.WriteString("/*\n")
-	file.WriteString("rule represents a grammar rule of the language. Its lhs is a single token while its rhs is a slice of tokens.\n")
-	file.WriteString("*/\n")
-	file.WriteString("type rule struct {\n")
-	file.WriteString("\tlhs uint16\n")
-	file.WriteString("\trhs []uint16\n")
-	file.WriteString("}\n\n")
-
 	file.WriteString("/*\n")
 	file.WriteString("The maximum length of the rhs of a rule of the language\n")
 	file.WriteString("*/\n")
 	file.WriteString(fmt.Sprintf("const _MAX_RHS_LEN = %d\n\n", maxRHSLen))
 
-	file.WriteString("/*\n")
-	file.WriteString("The rules of the language. They are sorted by their rhs\n")
-	file.WriteString("*/\n")
-	file.WriteString("var _RULES = []rule{\n")
-	for _, rule := range rules {
-		file.WriteString(fmt.Sprintf("\trule{%s, []uint16{%s}},\n", rule.LHS, strings.Join(rule.RHS, ", ")))
-	}
-	file.WriteString("}\n\n")
-
 	trie := createTrie(rules, nonterminals, terminals)
 	compressedTrie := trie.Compress(nonterminals, terminals)
 
+	file.WriteString("/*\n")
+	file.WriteString("The rules of the language, sorted by their rhs and stored in a compressed trie\n")
+	file.WriteString("*/\n")
 	file.WriteString("var compressedTrie = []uint16{")
 	if len(compressedTrie) > 0 {
 		file.WriteString(fmt.Sprintf("%d", compressedTrie[0]))
@@ -237,75 +248,35 @@ func emitRules(outdir string, rules []rule, nonterminals stringSet, terminals st
 	}
 	file.WriteString("}\n\n")
 
-	file.WriteString(
-		`/*
-findMatch tries to find a match for the rhs using the compressed trie above.
-On success it returns the corresponding lhs and the rule number.
-On failure it returns an error.
-*/
-func findMatch(rhs []uint16) (uint16, uint16) {
-	pos := uint16(0)
-
-	for _, key := range rhs {
-		//Skip the value and rule num for each node (except the last)
-		pos += 2
-		numIndices := compressedTrie[pos]
-		if numIndices == 0 {
-			return _EMPTY, 0
-		}
-		pos++
-		low := uint16(0)
-		high := uint16(numIndices - 1)
-		startPos := pos
-		foundNext := false
-
-		for low <= high {
-			indexpos := low + (high-low)/2
-			pos = startPos + indexpos*2
-			curKey := compressedTrie[pos]
-
-			if key < curKey {
-				high = indexpos - 1
-			} else if key > curKey {
-				low = indexpos + 1
-			} else {
-				pos = compressedTrie[pos+1]
-				foundNext = true
-				break
-			}
-		}
-		if !foundNext {
-			return _EMPTY, 0
-		}
-	}
-
-	return compressedTrie[pos], compressedTrie[pos+1]
-}`)
+	file.WriteString("/*\n")
+	file.WriteString("The synchronizing terminals, where the parser resumes after a syntax error\n")
+	file.WriteString("*/\n")
+	file.WriteString(fmt.Sprintf("var syncTerminals = []Token{%s}", strings.Join(syncTerminals, ", ")))
 
-	return nil
+	return GeneratedFile{"rules.go", file.Bytes()}
 }
 
-func emitFunction(outdir string, preamble string, rules []rule) error {
-	outPath := outdir + "/" + "function.go"
-	file, err := createFile(outPath)
-
-	if err != nil {
-		return err
-	}
-
-	defer file.Close()
-
-	packageName := path.Base(outdir)
+func emitFunction(packageName string, preamble string, rules []rule) GeneratedFile {
+	var file bytes.Buffer
 
 	file.WriteString(fmt.Sprintf("package %s\n\n", packageName))
 
 	file.WriteString(preamble)
 	file.WriteString("\n\n")
 
+	if !declares(preamble, "parserContext") {
+		file.WriteString("/*\n")
+		file.WriteString("parserContext contains the state of the semantic function of the parser owned by each Parser,\n")
+		file.WriteString("which the specification of the grammar did not declare.\n")
+		file.WriteString("*/\n")
+		file.WriteString("type parserContext struct{}\n\n")
+	}
+
 	file.WriteString("/*\n")
 	file.WriteString("function is the semantic function of the parser.\n")
+	file.WriteString("ctx is the parserContext of the Parser, initialized by parserPreallocMem before each parse.\n")
 	file.WriteString("*/\n")
-	file.WriteString("func function(thread int, ruleNum uint16, lhs *symbol, rhs []*symbol) {\n")
+	file.WriteString("func function(ctx *parserContext, thread int, ruleNum uint16, lhs *Symbol, rhs []*Symbol) {\n")
 	file.WriteString("\tswitch ruleNum {\n")
 	for i, rule := range rules {
 		file.WriteString(fmt.Sprintf("\tcase %d:\n", i))
@@ -336,80 +307,41 @@ func emitFunction(outdir string, preamble string, rules []rule) error {
 	file.WriteString("\t}\n")
 	file.WriteString("}\n")
 
-	return nil
+	return GeneratedFile{"function.go", file.Bytes()}
 }
 
-func emitPrecMatrix(outdir string, terminals stringSet, matrix precMatrix) error {
-	outPath := outdir + "/" + "matrix.go"
-	file, err := createFile(outPath)
-
-	if err != nil {
-		return err
-	}
-
-	defer file.Close()
-
-	packageName := path.Base(outdir)
+func emitPrecMatrix(packageName string, terminals stringSet, matrix precMatrix) GeneratedFile {
+	var file bytes.Buffer
 
 	file.WriteString(fmt.Sprintf("package %s\n\n", packageName))
 
-	file.WriteString("const _YI = _YIELDS_PREC\n")
-	file.WriteString("const _EQ = _EQ_PREC\n")
-	file.WriteString("const _TA = _TAKES_PREC\n")
-	file.WriteString("const _NO = _NO_PREC\n\n")
-
-	pureMatrix := make([][]uint16, len(terminals))
-
-	for i, terminal1 := range terminals {
-		pureMatrix[i] = make([]uint16, len(terminals))
-		for j, terminal2 := range terminals {
-			pureMatrix[i][j] = matrix[terminal1][terminal2]
-		}
-	}
-
-	file.WriteString("/*\n")
-	file.WriteString("The unpacked precedence matrix\n")
-	file.WriteString("*/\n")
-	file.WriteString("var _PREC_MATRIX [][]uint16 = [][]uint16{\n")
-	for i, _ := range terminals {
-		file.WriteString("\t[]uint16{")
-		for j, _ := range terminals {
-			if j < len(terminals)-1 {
-				file.WriteString(fmt.Sprintf("%s, ", precToString(pureMatrix[i][j])))
-			} else {
-				file.WriteString(precToString(pureMatrix[i][j]))
-			}
-		}
-		file.WriteString("},\n")
-	}
-	file.WriteString("}\n")
-
-	bitPackedMatrix := bitPack(pureMatrix)
+	bitPackedMatrix := packPrecMatrix(terminals, matrix)
 	file.WriteString("/*\n")
 	file.WriteString("The packed precedence matrix\n")
 	file.WriteString("*/\n")
-	file.WriteString("var _PREC_MATRIX_BITPACKED []uint64 = []uint64{\n\t")
+	file.WriteString("var _PREC_MATRIX_BITPACKED = []uint64{\n\t")
 	for _, v := range bitPackedMatrix {
 		file.WriteString(fmt.Sprintf("%d, ", v))
 	}
-	file.WriteString("\n}\n\n")
+	file.WriteString("\n}")
 
-	file.WriteString(
-		`/*
-getPrecedence returns the precedence between two tokens using the bitpacked precedence matrix.
-*/
-func getPrecedence(token1 uint16, token2 uint16) uint16 {
-	tv1 := tokenValue(token1)
-	tv2 := tokenValue(token2)
+	return GeneratedFile{"matrix.go", file.Bytes()}
+}
 
-	flatElemPos := tv1*_NUM_TERMINALS + tv2
-	elem := _PREC_MATRIX_BITPACKED[flatElemPos/32]
-	pos := uint((flatElemPos % 32) * 2)
+/*
+packPrecMatrix orders the precedence matrix by the terminals and packs it with bitPack.
+*/
+func packPrecMatrix(terminals stringSet, matrix precMatrix) []uint64 {
+	pureMatrix := make([][]uint16, len(terminals))
 
-	return uint16((elem >> pos) & 0x3)
-}`)
+	for i, terminal1 := range terminals {
+		pureMatrix[i] = make([]uint16, len(terminals))
+		for j, terminal2 := range terminals {
+			pureMatrix[i][j] = matrix[terminal1][terminal2]
+		}
+	}
 
-	return nil
+	return bitPack(pureMatrix)
 }
 
 /*
@@ -437,52 +369,305 @@ func bitPack(matrix [][]uint16) []uint64 {
 	return newMatrix
 }
 
-func emitCommonFiles(outdir string) error {
-	dir := "common"
-	files, err := ioutil.ReadDir(dir)
+/*
+runtimeImportPath is the import path of the runtime package used by the generated parsers.
+*/
+const runtimeImportPath = "github.com/simoneguidi94/gopapageno/papageno"
+
+/*
+emitParser emits the file that binds the tables and the semantic functions of the language
+to the runtime package, and that provides the aliases used by the semantic actions of the specifications.
+If hasLexer is false the language has no lexer, so the Parser only provides ParseTokens.
+*/
+func emitParser(packageName string, hasLexer bool) GeneratedFile {
+	var file bytes.Buffer
+
+	file.WriteString(fmt.Sprintf("package %s\n\n", packageName))
+
+	if hasLexer {
+		file.WriteString("import (\n\t\"io/ioutil\"\n\t\"os\"\n\n")
+	} else {
+		file.WriteString("import (\n\t\"os\"\n\n")
+	}
+	file.WriteString(fmt.Sprintf("\t\"%s\"\n)\n\n", runtimeImportPath))
+
+	file.WriteString(`/*
+Symbol contains a token and its value, a precedence and pointers to build the syntactic tree.
+*/
+type Symbol = papageno.Symbol
+
+/*
+Token identifies a terminal or a nonterminal of the grammar.
+*/
+type Token = papageno.Token
+
+/*
+symbol is an alias of Symbol, so that the semantic actions of the specifications
+may keep using the unexported name.
+*/
+type symbol = Symbol
+
+/*
+The results of the semantic function of the lexer.
+*/
+const (
+	_ERROR       = papageno.LexError
+	_END_OF_FILE = papageno.LexEndOfFile
+	_LEX_CORRECT = papageno.LexCorrect
+	_SKIP        = papageno.LexSkip
+)
+
+/*
+int64Pool allows to preallocate elements with type int64 in the semantic functions.
+*/
+type int64Pool = papageno.Int64Pool
+
+func newInt64Pool(length int) *int64Pool {
+	return papageno.NewInt64Pool(length)
+}
+
+`)
+
+	if hasLexer {
+		file.WriteString(`var lexer = papageno.Lexer{
+	Automata:           lexerAutomata,
+	CutPointsAutomaton: cutPointsAutomaton,
+}
+
+`)
+	}
+
+	file.WriteString(`var grammar = papageno.Grammar{
+	NumTerminals:   _NUM_TERMINALS,
+	MaxRHSLen:      _MAX_RHS_LEN,
+	Empty:          _EMPTY,
+	Term:           _TERM,
+	CompressedTrie: compressedTrie,
+	PrecMatrix:     _PREC_MATRIX_BITPACKED,
+	TokenString:    tokenToString,
+	SyncTerminals:  syncTerminals,
+}
+
+/*
+Parser parses the language in parallel. It owns the memory pools of the parser and of the semantic functions,
+the statistics and the configuration of its parses, so different Parsers may be used at the same time
+by different goroutines, while a Parser must not be used by more than one goroutine at a time.
+The memory pools are kept between the parses, so the tree returned by a parse is valid
+until the next parse, Reset or Release.
+*/
+type Parser struct {
+	parser papageno.Parser
+`)
+
+	if hasLexer {
+		file.WriteString("\tlexerCtx  lexerContext\n")
+	}
+
+	file.WriteString(`	parserCtx parserContext
+}
+
+/*
+NewParser creates a Parser, whose semantic functions use its own lexerContext and parserContext.
+*/
+func NewParser() *Parser {
+	p := &Parser{}
+`)
+
+	if hasLexer {
+		file.WriteString(`
+	l := lexer
+	l.Function = func(thread int, ruleNum int, yytext string, genSym *Symbol, condition *int) int {
+		return lexerFunction(&p.lexerCtx, thread, ruleNum, yytext, genSym, condition)
+	}
+	l.PreallocMem = func(inputSize int, numThreads int) {
+		lexerPreallocMem(&p.lexerCtx, inputSize, numThreads)
+	}
+	p.parser.Lexer = &l
+`)
+	}
+
+	file.WriteString(`
+	g := grammar
+	g.Function = func(thread int, ruleNum uint16, lhs *Symbol, rhs []*Symbol) {
+		function(&p.parserCtx, thread, ruleNum, lhs, rhs)
+	}
+	g.PreallocMem = func(inputSize int, numThreads int) {
+		parserPreallocMem(&p.parserCtx, inputSize, numThreads)
+	}
+	p.parser.Grammar = &g
+
+	return p
+}
+
+/*
+Stats returns the statistics about the last parse.
+*/
+func (p *Parser) Stats() *papageno.ParsingStats {
+	return &p.parser.Stats
+}
+
+/*
+SetCPUProfileFile sets the file where a CPU profile of the parsing phase is written.
+*/
+func (p *Parser) SetCPUProfileFile(file *os.File) {
+	p.parser.CPUProfileFile = file
+}
+
+/*
+SetForceGC sets whether the garbage collector is run during each parse, after the memory pools are allocated
+and before the input is processed, so that it does not run concurrently with the lexer and the parser.
+It is not run by default.
+*/
+func (p *Parser) SetForceGC(force bool) {
+	p.parser.ForceGC = force
+}
+
+/*
+Reset clears the memory pools of the parser, so that they do not reference the tree of the last parse anymore,
+while keeping them for the next parse.
+`)
+
+	if hasLexer {
+		file.WriteString(`The memory pools of the semantic functions, in lexerContext and parserContext, are not cleared,
+since they are managed by lexerPreallocMem and parserPreallocMem, which reuse them at the beginning
+of the next parse. Release drops them as well.
+`)
+	} else {
+		file.WriteString(`The memory pools of the semantic functions, in parserContext, are not cleared,
+since they are managed by parserPreallocMem, which reuses them at the beginning
+of the next parse. Release drops them as well.
+`)
+	}
+
+	file.WriteString(`*/
+func (p *Parser) Reset() {
+	p.parser.Reset()
+}
+
+/*
+Release drops the memory pools of the parser and of the semantic functions,
+so that they can be collected by the garbage collector. The next parse allocates them again.
+*/
+func (p *Parser) Release() {
+	p.parser.Release()
+`)
+
+	if hasLexer {
+		file.WriteString("\tp.lexerCtx = lexerContext{}\n")
+	}
+
+	file.WriteString(`	p.parserCtx = parserContext{}
+}
+
+/*
+ParseTokens parses in parallel a list of symbols that were already lexed, using an operator precedence grammar.
+It returns the symbol at the root of the syntactic tree, or an error if the symbols could not be parsed.
+*/
+func (p *Parser) ParseTokens(tokens []Symbol, numThreads int) (*Symbol, error) {
+	return p.parser.ParseTokens(tokens, numThreads)
+}`)
+
+	if hasLexer {
+		file.WriteString(`
+
+/*
+ParseString parses a string in parallel using an operator precedence grammar.
+It takes as input a string as a slice of bytes and the number of threads, and returns
+the symbol at the root of the syntactic tree, or an error if the string could not be parsed.
+*/
+func (p *Parser) ParseString(str []byte, numThreads int) (*Symbol, error) {
+	return p.parser.ParseString(str, numThreads)
+}
+
+/*
+ParseFile parses a file in parallel using an operator precedence grammar.
+It takes as input a filename and the number of threads, and returns
+the symbol at the root of the syntactic tree, or an error if the file could not be parsed.
+*/
+func (p *Parser) ParseFile(filename string, numThreads int) (*Symbol, error) {
+	bytes, err := ioutil.ReadFile(filename)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	for _, fileInfo := range files {
-		if !fileInfo.IsDir() {
-			inPath := dir + "/" + fileInfo.Name()
-			outPath := outdir + "/" + fileInfo.Name()
+	return p.ParseString(bytes, numThreads)
+}`)
+	}
 
-			inFileContent, err := ioutil.ReadFile(inPath)
+	return GeneratedFile{"parser.go", file.Bytes()}
+}
 
-			if err != nil {
-				return err
-			}
+/*
+generatedHeader returns the comment that marks the emitted files as generated,
+as described in https://golang.org/s/generatedcode.
+Only the base names of the specifications are used, so that the output does not depend
+on the directory the generator is run from.
+*/
+func generatedHeader(lexerFilename string, parserFilename string) string {
+	if lexerFilename == "" {
+		return fmt.Sprintf("// Code generated by papageno from %s. DO NOT EDIT.\n\n", filepath.Base(parserFilename))
+	}
+	return fmt.Sprintf("// Code generated by papageno from %s and %s. DO NOT EDIT.\n\n", filepath.Base(lexerFilename), filepath.Base(parserFilename))
+}
 
-			outFile, err := createFile(outPath)
+/*
+formatGeneratedFile adds the header to a generated file and formats it like gofmt does.
+It fails if the file is not valid Go code, which usually means that one of
+the semantic actions of the specifications is malformed.
+*/
+func formatGeneratedFile(header string, file GeneratedFile) (GeneratedFile, error) {
+	content := append([]byte(header), file.Content...)
 
-			if err != nil {
-				return err
-			}
+	formattedContent, err := format.Source(content)
 
-			packageName := path.Base(outdir)
+	if err != nil {
+		return file, fmt.Errorf("%s: %w", file.Name, err)
+	}
 
-			outFile.WriteString(fmt.Sprintf("package %s\n\n", packageName))
-			outFile.Write(inFileContent)
+	return GeneratedFile{file.Name, formattedContent}, nil
+}
 
-			outFile.Close()
+/*
+writeGeneratedFiles writes the generated files in outdir, creating it if needed.
+*/
+func writeGeneratedFiles(outdir string, files []GeneratedFile, logger *slog.Logger) error {
+	err := emitOutputFolder(outdir, logger)
+
+	if err != nil {
+		return err
+	}
+
+	for _, generatedFile := range files {
+		file, err := createFile(filepath.Join(outdir, generatedFile.Name), logger)
+
+		if err != nil {
+			return err
+		}
+
+		_, err = file.Write(generatedFile.Content)
+		closeErr := file.Close()
+
+		if err != nil {
+			return err
+		}
+		if closeErr != nil {
+			return closeErr
 		}
 	}
 
 	return nil
 }
 
-func createFile(path string) (*os.File, error) {
+func createFile(path string, logger *slog.Logger) (*os.File, error) {
 	fileExisted := fileExists(path)
 	file, err := os.Create(path)
 
 	if err == nil {
 		if fileExisted {
-			fmt.Printf("Overwritten file %s\n", path)
+			logger.Info("overwritten file", "file", path)
 		} else {
-			fmt.Printf("Created file %s\n", path)
+			logger.Info("created file", "file", path)
 		}
 	}
 
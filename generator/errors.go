@@ -0,0 +1,268 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+/*
+ErrNoLexRules is returned when the lexer specification does not contain any rule.
+*/
+var ErrNoLexRules = errors.New("the lexer does not contain any rule")
+
+/*
+SyntaxError is returned when a specification file is malformed.
+Line and Column locate the problem in the file, and are 0 if its position is unknown.
+Err describes the problem and may be one of the other error types of this package,
+such as *MissingDefinitionError or *RegexParseError.
+*/
+type SyntaxError struct {
+	Filename string
+	Line     int
+	Column   int
+	Err      error
+}
+
+func newSyntaxError(filename string, pos position, err error) *SyntaxError {
+	return &SyntaxError{filename, pos.Line, pos.Column, err}
+}
+
+func (e *SyntaxError) Error() string {
+	if e.Line == 0 {
+		return fmt.Sprintf("%s: %s", e.Filename, e.Err.Error())
+	}
+	return fmt.Sprintf("%s:%d:%d: %s", e.Filename, e.Line, e.Column, e.Err.Error())
+}
+
+func (e *SyntaxError) Unwrap() error {
+	return e.Err
+}
+
+/*
+ErrorList is returned when several errors are found in the specifications at once.
+Its Error method returns one error per line.
+*/
+type ErrorList []*SyntaxError
+
+func (l ErrorList) Error() string {
+	msgs := make([]string, len(l))
+	for i, e := range l {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+/*
+Unwrap returns the errors of the list, so that errors.As finds the first one of a given type.
+*/
+func (l ErrorList) Unwrap() []error {
+	errs := make([]error, len(l))
+	for i, e := range l {
+		errs[i] = e
+	}
+	return errs
+}
+
+/*
+Err returns nil if the list is empty, its only error if it contains one, or the list itself.
+*/
+func (l ErrorList) Err() error {
+	switch len(l) {
+	case 0:
+		return nil
+	case 1:
+		return l[0]
+	}
+	return l
+}
+
+/*
+appendErrors appends err to the list if it is a *SyntaxError or an ErrorList.
+It returns false if err is of another kind, which prevents any further check.
+*/
+func appendErrors(l ErrorList, err error) (ErrorList, bool) {
+	switch e := err.(type) {
+	case *SyntaxError:
+		return append(l, e), true
+	case ErrorList:
+		return append(l, e...), true
+	}
+	return l, false
+}
+
+/*
+MissingDefinitionError is returned when a lexer rule or a definition references a definition that does not exist.
+Definition is the name of the definition containing the reference, or is empty if it is in a rule.
+*/
+type MissingDefinitionError struct {
+	Name       string
+	Definition string
+}
+
+func (e *MissingDefinitionError) Error() string {
+	if e.Definition != "" {
+		return fmt.Sprintf("missing definition %q, referenced by %s", e.Name, e.Definition)
+	}
+	return fmt.Sprintf("missing definition %q", e.Name)
+}
+
+/*
+DefinitionCycleError is returned when some definitions of the lexer refer to each other.
+Cycle contains the names of the definitions involved, starting and ending with the same one.
+*/
+type DefinitionCycleError struct {
+	Cycle []string
+}
+
+func (e *DefinitionCycleError) Error() string {
+	return fmt.Sprintf("the definitions refer to each other: %s", strings.Join(e.Cycle, " -> "))
+}
+
+/*
+RegexParseError is returned when a regular expression of the lexer cannot be parsed.
+Rule is the number of the lexer rule containing the regular expression,
+or -1 if the regular expression is the one describing the cut points.
+*/
+type RegexParseError struct {
+	Regex string
+	Rule  int
+}
+
+func (e *RegexParseError) Error() string {
+	if e.Rule < 0 {
+		return fmt.Sprintf("could not parse the cut points regular expression: %s", e.Regex)
+	}
+	return fmt.Sprintf("could not parse the regular expression of lexer rule %d: %s", e.Rule, e.Regex)
+}
+
+/*
+StartConditionError is returned when a start condition of the lexer is used but not declared,
+or when it is declared but no rule is active in it.
+*/
+type StartConditionError struct {
+	Name string
+	Msg  string
+}
+
+func (e *StartConditionError) Error() string {
+	return fmt.Sprintf("the start condition %s %s", e.Name, e.Msg)
+}
+
+/*
+MissingAxiomError is returned when the axiom of the grammar is not defined,
+or when it is defined but it is not the lhs of any rule.
+*/
+type MissingAxiomError struct {
+	Axiom string
+}
+
+func (e *MissingAxiomError) Error() string {
+	if e.Axiom == "" {
+		return "the axiom is not defined"
+	}
+	return fmt.Sprintf("the axiom %s isn't used in any rule", e.Axiom)
+}
+
+/*
+SyncTerminalError is returned when a synchronizing terminal declared with %sync
+is not a terminal of the grammar.
+*/
+type SyncTerminalError struct {
+	Name string
+}
+
+func (e *SyncTerminalError) Error() string {
+	return fmt.Sprintf("the synchronizing terminal %s is not a terminal of the grammar", e.Name)
+}
+
+/*
+OperatorFormError is returned when a rule of the grammar is not in operator precedence form,
+that is when its rhs contains two adjacent nonterminals.
+*/
+type OperatorFormError struct {
+	Rule string
+}
+
+func (e *OperatorFormError) Error() string {
+	return fmt.Sprintf("the rule %s is not in operator precedence form", e.Rule)
+}
+
+/*
+PrecedenceConflictError is returned when more than one precedence relation
+holds between two terminals.
+*/
+type PrecedenceConflictError struct {
+	Terminal1 string
+	Terminal2 string
+	Existing  uint16
+	New       uint16
+}
+
+func (e *PrecedenceConflictError) Error() string {
+	return fmt.Sprintf("the precedence relation is not unique between %s and %s (%s and %s)", e.Terminal1, e.Terminal2, precToString(e.Existing), precToString(e.New))
+}
+
+/*
+TokenInferenceError is returned by NewInterpreter when the terminal produced by a lexer rule
+cannot be inferred from its action, because the action names no terminal or more than one.
+*/
+type TokenInferenceError struct {
+	Rule      int
+	Regex     string
+	Terminals []string
+}
+
+func (e *TokenInferenceError) Error() string {
+	if len(e.Terminals) == 0 {
+		return fmt.Sprintf("cannot infer the token of lexer rule %d (%s): its action names no terminal", e.Rule, e.Regex)
+	}
+	return fmt.Sprintf("cannot infer the token of lexer rule %d (%s): its action names %s", e.Rule, e.Regex, strings.Join(e.Terminals, ", "))
+}
+
+/*
+ActionError is returned by NewInterpreter when a callback is supplied for a terminal
+or a rule that does not exist, or that is never used by the parser.
+*/
+type ActionError struct {
+	Key string
+	Msg string
+}
+
+func (e *ActionError) Error() string {
+	return fmt.Sprintf("action %q: %s", e.Key, e.Msg)
+}
+
+/*
+AutomatonTooLargeError is returned when a lexer automaton has more states than its table can index.
+*/
+type AutomatonTooLargeError struct {
+	Name   string
+	States int
+}
+
+func (e *AutomatonTooLargeError) Error() string {
+	return fmt.Sprintf("the lexer automaton %s has %d states, more than the %d supported", e.Name, e.States, maxAutomatonStates)
+}
+
+/*
+WarningError is a warning about a specification reported as an error, when Options.WarningsAsErrors is set.
+*/
+type WarningError struct {
+	Msg string
+}
+
+func (e *WarningError) Error() string {
+	return e.Msg
+}
+
+/*
+warningsToErrors returns the warnings as a *SyntaxError or, if there are several, an ErrorList.
+*/
+func warningsToErrors(warnings []Warning) error {
+	errs := make(ErrorList, len(warnings))
+	for i, w := range warnings {
+		errs[i] = &SyntaxError{w.Filename, w.Line, w.Column, &WarningError{w.Msg}}
+	}
+	return errs.Err()
+}
@@ -1,155 +1,586 @@
 package generator
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"go/token"
+	"io"
+	"io/ioutil"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/simoneguidi94/gopapageno/generator/regex"
 )
 
-func Generate(lexerFilename string, parserFilename string, outdir string) {
-	lexRules, cutPoints, lexCode := parseLexer(lexerFilename)
+/*
+analysis contains everything that is inferred from a lexer and a grammar specification
+before any file is emitted.
+*/
+type analysis struct {
+	lexRules      []lexRule
+	lexCode       string
+	conditions    []lexCondition
+	dfas          []regex.Dfa
+	cutPointsDfa  regex.Dfa
+	automataStats []automatonStats
+	ruleOverlaps  []ruleOverlap
 
-	fmt.Printf("Lex rules (%d):\n", len(lexRules))
-	for _, r := range lexRules {
-		fmt.Println(r)
+	preamble      string
+	axiom         string
+	rules         []rule
+	nonterminals  stringSet
+	terminals     stringSet
+	sortedRules   []rule
+	precMatrix    precMatrix
+	syncTerminals stringSet
+
+	warnings []Warning
+}
+
+/*
+analyze reads the lexer and the grammar specifications and performs every step
+required to generate a parser, without emitting anything.
+If lexerFilename is empty only the grammar is analyzed.
+The warnings and the intermediate stages are reported to d.
+*/
+func analyze(lexerFilename string, parserFilename string, d *diagnostics) (*analysis, error) {
+	a := &analysis{}
+
+	//The syntax errors of both the specifications are reported together
+	errs := make(ErrorList, 0)
+
+	if lexerFilename != "" {
+		err := analyzeLexer(a, lexerFilename, d)
+
+		if err != nil {
+			var ok bool
+			if errs, ok = appendErrors(errs, err); !ok {
+				return nil, err
+			}
+		}
+	}
+
+	parserPreamble, axiom, syncTerminals, rules, err := parseGrammar(parserFilename, d)
+
+	if err != nil {
+		var ok bool
+		if errs, ok = appendErrors(errs, err); !ok {
+			return nil, err
+		}
 	}
 
-	fmt.Printf("Cut points regex: %s\n", cutPoints)
+	if len(errs) > 0 {
+		return nil, errs.Err()
+	}
 
-	fmt.Println("Lex code:")
-	fmt.Println(lexCode)
+	d.Dump("go preamble", lines{parserPreamble})
 
-	var dfa regex.Dfa
+	if axiom == "" {
+		return nil, &MissingAxiomError{""}
+	}
 
-	if len(lexRules) > 0 {
-		var nfa *regex.Nfa
-		success, result := regex.ParseString([]byte(lexRules[0].Regex), 1)
-		if success {
-			nfa = result.Value.(*regex.Nfa)
-			nfa.AddAssociatedRule(0)
+	d.logger.Info("parsed grammar", "file", parserFilename, "axiom", axiom, "rules", len(rules))
+	d.Dump("rules", ruleLines(rules))
+
+	a.preamble = parserPreamble
+	a.axiom = axiom
+	a.rules = rules
+
+	nonterminals, terminals := inferTokens(rules)
+
+	d.logger.Debug("inferred tokens", "nonterminals", nonterminals.String(), "terminals", terminals.String())
+
+	if !checkAxiomUsage(rules, axiom) {
+		return nil, &MissingAxiomError{axiom}
+	}
+
+	a.syncTerminals = newStringSet()
+	for _, s := range syncTerminals {
+		if !terminals.Contains(s.Name) || s.Name == "_TERM" {
+			errs = append(errs, newSyntaxError(parserFilename, s.Pos, &SyncTerminalError{s.Name}))
+		} else {
+			a.syncTerminals.Add(s.Name)
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errs.Err()
+	}
+
+	newRules, newNonterminals := deleteRepeatedRHS(nonterminals, terminals, axiom, rules, d)
+
+	d.logger.Info("eliminated repeated rhs", "rules", len(newRules), "nonterminals", len(newNonterminals))
+	d.Dump("new rules", ruleLines(newRules))
+	d.logger.Debug("new nonterminals", "nonterminals", newNonterminals.String())
+
+	a.nonterminals = newNonterminals
+	a.terminals = terminals
+
+	precMatrix, err := createPrecMatrix(newRules, newNonterminals, terminals, d)
+
+	if err != nil {
+		return nil, err
+	}
+
+	d.Dump("precedence matrix", precMatrix)
+
+	a.precMatrix = precMatrix
+
+	sortedRules := sortRulesByRHS(newRules, newNonterminals, terminals)
+	d.Dump("sorted rules", ruleLines(sortedRules))
+
+	a.sortedRules = sortedRules
+	a.warnings = d.warnings
+
+	return a, nil
+}
+
+/*
+analyzeLexer reads the lexer specification and builds the automata of the lexer and of the cut points.
+*/
+func analyzeLexer(a *analysis, lexerFilename string, d *diagnostics) error {
+	lexRules, conditions, cutPoints, lexCode, opts, err := parseLexer(lexerFilename, d)
+
+	if err != nil {
+		return err
+	}
+
+	d.logger.Info("parsed lexer", "file", lexerFilename, "rules", len(lexRules), "conditions", len(conditions))
+	lexRuleLines := make(lines, len(lexRules))
+	for i, r := range lexRules {
+		lexRuleLines[i] = r.String()
+	}
+	d.Dump("lex rules", lexRuleLines)
+	d.logger.Debug("cut points", "regex", cutPoints.Regex)
+	d.Dump("lex code", lines{lexCode})
+
+	a.lexRules = lexRules
+	a.lexCode = lexCode
+	a.conditions = conditions
+
+	if len(lexRules) == 0 {
+		return ErrNoLexRules
+	}
+
+	errs := make(ErrorList, 0)
+
+	if err := checkConditions(lexerFilename, lexRules, conditions); err != nil {
+		errs, _ = appendErrors(errs, err)
+	}
+
+	//Parse every regular expression once, so that all the invalid ones are reported
+	nfas := make([]*regex.Nfa, len(lexRules))
+	for i, r := range lexRules {
+		result, err := regex.ParseStringWithOptions([]byte(r.Regex), 1, opts)
+		if err != nil {
+			errs = append(errs, newSyntaxError(lexerFilename, r.Pos, &RegexParseError{r.Regex, i}))
+			continue
+		}
+		nfas[i] = result.Value.(*regex.Nfa)
+	}
+
+	var cutPointsNfa *regex.Nfa
+	if cutPoints.Regex == "" {
+		emptyNfa := regex.NewEmptyStringNfa()
+		cutPointsNfa = &emptyNfa
+	} else {
+		result, err := regex.ParseStringWithOptions([]byte(cutPoints.Regex), 1, opts)
+		if err == nil {
+			cutPointsNfa = result.Value.(*regex.Nfa)
 		} else {
-			fmt.Println("Error: could not parse the following regular expression:", lexRules[0].Regex)
-			return
+			errs = append(errs, newSyntaxError(lexerFilename, cutPoints.Pos, &RegexParseError{cutPoints.Regex, -1}))
 		}
-		for i := 1; i < len(lexRules); i++ {
-			var curNfa *regex.Nfa
-			success, result = regex.ParseString([]byte(lexRules[i].Regex), 1)
-			if success {
+	}
+
+	if len(errs) > 0 {
+		return errs.Err()
+	}
+
+	//Build an automaton for each start condition, from the rules that are active in it.
+	//The automata of the rules are parsed again since uniting them modifies them
+	a.dfas = make([]regex.Dfa, len(conditions))
+
+	for c, condition := range conditions {
+		var nfa *regex.Nfa
+		for i, r := range lexRules {
+			if !isActive(r.Conditions, condition) {
+				continue
+			}
+			curNfa := nfas[i]
+			if curNfa == nil {
+				result, _ := regex.ParseStringWithOptions([]byte(r.Regex), 1, opts)
 				curNfa = result.Value.(*regex.Nfa)
-				curNfa.AddAssociatedRule(i)
-				nfa.Unite(*curNfa)
+			}
+			nfas[i] = nil
+			curNfa.AddAssociatedRule(i)
+			if nfa == nil {
+				nfa = curNfa
 			} else {
-				fmt.Println("Error: could not parse the following regular expression:", lexRules[i].Regex)
-				return
+				nfa.Unite(*curNfa)
 			}
 		}
 
-		dfa = nfa.ToDfa()
+		a.dfas[c] = a.minimize(condition.Name, nfa, d)
+		if a.dfas[c].NumStates > maxAutomatonStates {
+			return &AutomatonTooLargeError{condition.Name, a.dfas[c].NumStates}
+		}
+	}
 
-		/*ok, hasRuleNum, ruleNum := dfa.Check([]byte(" "))
-		if ok {
-			fmt.Println("Ok")
-			if hasRuleNum {
-				fmt.Println("RuleNum:", ruleNum)
-			} else {
-				fmt.Println("No rule")
-			}
-		} else {
-			fmt.Println("Not ok")
-		}*/
-	} else {
-		fmt.Println("Error: the lexer does not contain any rule")
-		return
+	a.cutPointsDfa = a.minimize("cut points", cutPointsNfa, d)
+	if a.cutPointsDfa.NumStates > maxAutomatonStates {
+		return &AutomatonTooLargeError{"cut points", a.cutPointsDfa.NumStates}
 	}
 
-	var cutPointsDfa regex.Dfa
-	if cutPoints == "" {
-		cutPointsNfa := regex.NewEmptyStringNfa()
-		cutPointsDfa = cutPointsNfa.ToDfa()
-	} else {
-		var cutPointsNfa *regex.Nfa
-		success, result := regex.ParseString([]byte(cutPoints), 1)
-		if success {
-			cutPointsNfa = result.Value.(*regex.Nfa)
-		} else {
-			fmt.Println("Error: could not parse the following regular expression:", cutPoints)
-			return
+	a.ruleOverlaps = checkLexRules(lexerFilename, lexRules, a.dfas, d)
+	d.Dump("overlapping lexer rules", overlapLines(a.ruleOverlaps, lexRules))
+
+	return nil
+}
+
+/*
+overlapLines returns the pairs of overlapping lexer rules as a list of lines,
+each with the lines of the rules and an example of the strings they both match.
+*/
+func overlapLines(overlaps []ruleOverlap, lexRules []lexRule) lines {
+	l := make(lines, len(overlaps))
+	for i, o := range overlaps {
+		l[i] = fmt.Sprintf("line %d and line %d: %q", lexRules[o.Chosen].Pos.Line, lexRules[o.Other].Pos.Line, o.Example)
+	}
+	return l
+}
+
+/*
+automatonStats contains the number of states of a lexer automaton before and after its minimization.
+*/
+type automatonStats struct {
+	Name            string
+	States          int
+	MinimizedStates int
+}
+
+/*
+minimize converts an automaton of the lexer to a minimal deterministic automaton,
+recording the number of states before and after the minimization.
+*/
+func (a *analysis) minimize(name string, nfa *regex.Nfa, d *diagnostics) regex.Dfa {
+	dfa := nfa.SubsetConstruction()
+	minimized := dfa.Minimize()
+
+	a.automataStats = append(a.automataStats, automatonStats{name, dfa.NumStates, minimized.NumStates})
+	d.logger.Info("built lexer automaton", "automaton", name, "states", dfa.NumStates, "minimized", minimized.NumStates)
+
+	return minimized
+}
+
+/*
+Options contains the parameters of a parser generation.
+*/
+type Options struct {
+	//LexerFile is the path of the lexer specification (.l).
+	//If it is empty only the parser is generated, and the symbols must be lexed
+	//by hand and passed to the generated ParseTokens function.
+	LexerFile string
+	//GrammarFile is the path of the grammar specification (.g)
+	GrammarFile string
+	//OutDir is the directory where the generated files are written.
+	//If it is empty the files are only returned.
+	OutDir string
+	//PackageName is the name of the generated package.
+	//If it is empty the name of OutDir is used.
+	PackageName string
+	//Prefix, if not empty, is added to every package level identifier and file name of the generated parser,
+	//so that several parsers can be generated in the same package.
+	//For example, with the prefix expr the type Parser becomes ExprParser, the function NewParser
+	//becomes ExprNewParser and the file tokens.go becomes expr_tokens.go.
+	Prefix string
+	//WarningsAsErrors makes the generation fail if there are warnings about the specifications,
+	//which are returned as errors.
+	WarningsAsErrors bool
+	//Logger receives the warnings, the progress of the generation at the info level
+	//and the intermediate stages of the analysis at the debug level.
+	//If it is nil nothing is logged.
+	Logger *slog.Logger
+}
+
+/*
+GeneratedFile is a file produced by the generator.
+Name is the name of the file relative to the output directory.
+*/
+type GeneratedFile struct {
+	Name    string
+	Content []byte
+}
+
+/*
+Result contains the outcome of a successful parser generation:
+the generated files and the warnings about the specifications.
+*/
+type Result struct {
+	Files    []GeneratedFile
+	Warnings []Warning
+}
+
+/*
+GenerateWithOptions generates a parser as described by opts and returns the generated files.
+If opts.OutDir is not empty the files are also written there.
+The returned error, if any, is one of the error types of this package
+(such as *MissingDefinitionError, *OperatorFormError or *PrecedenceConflictError),
+or an error caused by reading the specifications or writing the files.
+*/
+func GenerateWithOptions(opts Options) (*Result, error) {
+	d := newDiagnostics(opts.Logger)
+
+	files, err := generateFiles(opts, d)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.OutDir != "" {
+		err = writeGeneratedFiles(opts.OutDir, files, d.logger)
+
+		if err != nil {
+			return nil, err
 		}
-		cutPointsDfa = cutPointsNfa.ToDfa()
 	}
 
-	parserPreamble, axiom, rules := parseGrammar(parserFilename)
+	return &Result{files, d.warnings}, nil
+}
 
-	fmt.Println("Go preamble:")
-	fmt.Println(parserPreamble)
+/*
+StaleFile describes a file of the output directory whose content differs from the generated one.
+Diff contains the differences in the unified format.
+*/
+type StaleFile struct {
+	Name string
+	Diff string
+}
 
-	if axiom == "" {
-		fmt.Println("Error: the axiom is not defined")
-		return
-	} else {
-		fmt.Println("Axiom:", axiom)
+/*
+Verify generates in memory the parser described by opts and compares it with the files in opts.OutDir,
+without modifying them. It returns the files that are missing or out of date,
+so an empty result means that the parser in opts.OutDir is up to date.
+*/
+func Verify(opts Options) ([]StaleFile, error) {
+	if opts.OutDir == "" {
+		return nil, errors.New("the output directory to verify must be specified")
 	}
 
-	fmt.Printf("Rules (%d):\n", len(rules))
-	for _, r := range rules {
-		fmt.Println(r)
+	files, err := generateFiles(opts, newDiagnostics(opts.Logger))
+
+	if err != nil {
+		return nil, err
 	}
 
-	nonterminals, terminals := inferTokens(rules)
+	staleFiles := make([]StaleFile, 0)
 
-	fmt.Printf("Nonterminals (%d): %s\n", len(nonterminals), nonterminals)
-	fmt.Printf("Terminals (%d): %s\n", len(terminals), terminals)
+	for _, file := range files {
+		outPath := filepath.Join(opts.OutDir, file.Name)
+		oldName := outPath
 
-	if !checkAxiomUsage(rules, axiom) {
-		fmt.Println("Error: the axiom isn't used in any rule")
-		return
+		content, err := ioutil.ReadFile(outPath)
+
+		if os.IsNotExist(err) {
+			oldName = outPath + " (missing)"
+		} else if err != nil {
+			return nil, err
+		}
+
+		if !bytes.Equal(content, file.Content) {
+			diff := unifiedDiff(oldName, outPath+" (generated)", content, file.Content)
+			staleFiles = append(staleFiles, StaleFile{file.Name, diff})
+		}
 	}
 
-	newRules, newNonterminals := deleteRepeatedRHS(nonterminals, terminals, axiom, rules)
+	return staleFiles, nil
+}
+
+/*
+generateFiles generates in memory the files of the parser described by opts.
+*/
+func generateFiles(opts Options, d *diagnostics) ([]GeneratedFile, error) {
+	packageName := opts.PackageName
 
-	fmt.Printf("New rules after elimination of repeated rhs (%d):\n", len(newRules))
-	for _, r := range newRules {
-		fmt.Println(r)
+	if packageName == "" {
+		if opts.OutDir == "" {
+			return nil, errors.New("either the output directory or the package name must be specified")
+		}
+		absOutdir, err := filepath.Abs(opts.OutDir)
+		if err != nil {
+			return nil, err
+		}
+		packageName = filepath.Base(absOutdir)
 	}
 
-	fmt.Printf("New nonterminals (%d): %s\n", len(newNonterminals), newNonterminals)
+	if opts.Prefix != "" && !token.IsIdentifier(opts.Prefix) {
+		return nil, fmt.Errorf("the prefix %q is not a valid identifier", opts.Prefix)
+	}
 
-	precMatrix, err := createPrecMatrix(newRules, newNonterminals, terminals)
+	a, err := analyze(opts.LexerFile, opts.GrammarFile, d)
 
 	if err != nil {
-		fmt.Println(err.Error())
-		return
+		return nil, err
 	}
 
-	fmt.Println("Precedence matrix:")
-	fmt.Println(precMatrix)
+	if opts.WarningsAsErrors && len(a.warnings) > 0 {
+		return nil, warningsToErrors(a.warnings)
+	}
 
-	sortedRules := sortRulesByRHS(newRules, newNonterminals, terminals)
-	fmt.Printf("Sorted rules (%d):\n", len(sortedRules))
-	for _, r := range sortedRules {
-		fmt.Println(r)
-	}
-
-	err = emitOutputFolder(outdir)
-	handleEmissionError(err)
-	err = emitLexerFunction(outdir, lexCode, lexRules)
-	handleEmissionError(err)
-	err = emitLexerAutomata(outdir, dfa, cutPointsDfa)
-	handleEmissionError(err)
-	err = emitTokens(outdir, newNonterminals, terminals)
-	handleEmissionError(err)
-	err = emitRules(outdir, sortedRules, newNonterminals, terminals)
-	handleEmissionError(err)
-	err = emitFunction(outdir, parserPreamble, sortedRules)
-	handleEmissionError(err)
-	err = emitPrecMatrix(outdir, terminals, precMatrix)
-	handleEmissionError(err)
-	err = emitCommonFiles(outdir)
-	handleEmissionError(err)
-}
-
-func handleEmissionError(e error) {
-	if e != nil {
-		fmt.Println(e.Error())
+	files := make([]GeneratedFile, 0)
+
+	hasLexer := opts.LexerFile != ""
+
+	if hasLexer {
+		files = append(files,
+			emitLexerFunction(packageName, a.lexCode, a.lexRules, a.conditions),
+			emitLexerAutomata(packageName, a.dfas, a.conditions, a.cutPointsDfa))
+	}
+
+	files = append(files,
+		emitTokens(packageName, a.nonterminals, a.terminals),
+		emitRules(packageName, a.sortedRules, a.nonterminals, a.terminals, a.syncTerminals),
+		emitFunction(packageName, a.preamble, a.sortedRules),
+		emitPrecMatrix(packageName, a.terminals, a.precMatrix),
+		emitParser(packageName, hasLexer))
+
+	if opts.Prefix != "" {
+		prefixer := newPrefixer(opts.Prefix, a.nonterminals, a.terminals, a.lexCode, a.preamble)
+
+		for i, file := range files {
+			files[i] = GeneratedFile{prefixer.RenameFile(file.Name), prefixer.RenameCode(file.Content)}
+		}
+	}
+
+	header := generatedHeader(opts.LexerFile, opts.GrammarFile)
+
+	for i := range files {
+		files[i], err = formatGeneratedFile(header, files[i])
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return files, nil
+}
+
+/*
+Generate generates a parser from a lexer and a grammar specification, writing its files in outdir.
+The generated files belong to the package packageName, or to a package named after outdir
+if packageName is empty.
+*/
+func Generate(lexerFilename string, parserFilename string, outdir string, packageName string) error {
+	_, err := GenerateWithOptions(Options{
+		LexerFile:   lexerFilename,
+		GrammarFile: parserFilename,
+		OutDir:      outdir,
+		PackageName: packageName,
+	})
+	return err
+}
+
+/*
+Check performs every validation done by Generate without writing any file.
+*/
+func Check(lexerFilename string, parserFilename string) error {
+	_, err := CheckWithOptions(Options{LexerFile: lexerFilename, GrammarFile: parserFilename})
+	return err
+}
+
+/*
+CheckWithOptions performs every validation done by GenerateWithOptions without generating any file,
+and returns the warnings about the specifications.
+Only the specifications, WarningsAsErrors and the logger of opts are used.
+*/
+func CheckWithOptions(opts Options) ([]Warning, error) {
+	a, err := analyze(opts.LexerFile, opts.GrammarFile, newDiagnostics(opts.Logger))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.WarningsAsErrors && len(a.warnings) > 0 {
+		return nil, warningsToErrors(a.warnings)
+	}
+
+	return a.warnings, nil
+}
+
+/*
+Explain writes to w a description of the grammar as seen by the generator:
+the inferred terminals and nonterminals, the rules obtained after the elimination
+of repeated right hand sides and the precedence matrix, followed by the number of states
+of the lexer automata and the pairs of lexer rules that match the same strings.
+*/
+func Explain(lexerFilename string, parserFilename string, w io.Writer) error {
+	return ExplainWithOptions(Options{LexerFile: lexerFilename, GrammarFile: parserFilename}, w)
+}
+
+/*
+ExplainWithOptions is like Explain, but it takes the specifications and the logger from opts.
+The warnings about the specifications, if any, are written at the end of the description.
+*/
+func ExplainWithOptions(opts Options, w io.Writer) error {
+	a, err := analyze(opts.LexerFile, opts.GrammarFile, newDiagnostics(opts.Logger))
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "Axiom: %s\n\n", a.axiom)
+
+	fmt.Fprintf(w, "Terminals (%d):\n", len(a.terminals))
+	for _, t := range a.terminals {
+		fmt.Fprintf(w, "\t%s\n", t)
+	}
+	fmt.Fprintln(w)
+
+	fmt.Fprintf(w, "Nonterminals (%d):\n", len(a.nonterminals))
+	for _, n := range a.nonterminals {
+		fmt.Fprintf(w, "\t%s\n", n)
+	}
+	fmt.Fprintln(w)
+
+	fmt.Fprintf(w, "Rules (%d):\n", len(a.sortedRules))
+	for i, r := range a.sortedRules {
+		fmt.Fprintf(w, "\t%d: %s\n", i, r)
+	}
+	fmt.Fprintln(w)
+
+	fmt.Fprintln(w, "Precedence matrix:")
+	writePrecMatrix(w, a.terminals, a.precMatrix)
+
+	if len(a.syncTerminals) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "Synchronizing terminals: %s\n", strings.Join(a.syncTerminals, ", "))
+	}
+
+	if len(a.automataStats) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Lexer automata (states before and after minimization):")
+		for _, stats := range a.automataStats {
+			fmt.Fprintf(w, "\t%s: %d -> %d\n", stats.Name, stats.States, stats.MinimizedStates)
+		}
 	}
+
+	if len(a.ruleOverlaps) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Overlapping lexer rules (the strings are lexed with the first rule):")
+		for _, line := range overlapLines(a.ruleOverlaps, a.lexRules) {
+			fmt.Fprintf(w, "\t%s\n", line)
+		}
+	}
+
+	if len(a.warnings) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "Warnings (%d):\n", len(a.warnings))
+		for _, warning := range a.warnings {
+			fmt.Fprintf(w, "\t%s\n", warning)
+		}
+	}
+
+	return nil
 }
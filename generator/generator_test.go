@@ -0,0 +1,1073 @@
+package generator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/simoneguidi94/gopapageno/generator/regex"
+	"github.com/simoneguidi94/gopapageno/papageno"
+)
+
+const testLexer = `%cut \n
+
+DIGIT [0-9]
+
+%%
+
+{DIGIT}+
+{
+	*genSym = symbol{Token: NUMBER}
+	return _LEX_CORRECT
+}
+\+
+{
+	*genSym = symbol{Token: PLUS}
+	return _LEX_CORRECT
+}
+
+%%
+func lexerPreallocMem(ctx *lexerContext, inputSize int, numThreads int) {
+}
+`
+
+const testGrammar = `func parserPreallocMem(ctx *parserContext, inputSize int, numThreads int) {
+}
+%%
+
+%axiom E
+
+%%
+
+E : E PLUS NUMBER
+{
+} | NUMBER
+{
+};
+`
+
+func writeSpec(t testing.TB, name string, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestGenerateWithOptions(t *testing.T) {
+	lexerFile := writeSpec(t, "test.l", testLexer)
+	grammarFile := writeSpec(t, "test.g", testGrammar)
+	outdir := filepath.Join(t.TempDir(), "test")
+
+	//The generator must not depend on the working directory
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	result, err := GenerateWithOptions(Options{
+		LexerFile:   lexerFile,
+		GrammarFile: grammarFile,
+		OutDir:      outdir,
+	})
+
+	if err != nil {
+		t.Fatalf("Error: %s", err.Error())
+	}
+
+	names := make(map[string]bool)
+	for _, file := range result.Files {
+		names[file.Name] = true
+
+		if _, err := os.Stat(filepath.Join(outdir, file.Name)); err != nil {
+			t.Errorf("Error: %s was not written: %s", file.Name, err.Error())
+		}
+	}
+	for _, name := range []string{"tokens.go", "rules.go", "matrix.go", "function.go", "lexerfunction.go", "lexerautomata.go", "parser.go"} {
+		if !names[name] {
+			t.Errorf("Error: %s was not generated", name)
+		}
+	}
+}
+
+func TestGenerateWithoutLexer(t *testing.T) {
+	result, err := GenerateWithOptions(Options{
+		GrammarFile: writeSpec(t, "test.g", testGrammar),
+		PackageName: "test",
+	})
+
+	if err != nil {
+		t.Fatalf("Error: %s", err.Error())
+	}
+
+	for _, file := range result.Files {
+		if strings.HasPrefix(file.Name, "lexer") {
+			t.Errorf("Error: %s was generated without a lexer", file.Name)
+		}
+		if file.Name == "parser.go" {
+			if !bytes.Contains(file.Content, []byte("func (p *Parser) ParseTokens(")) {
+				t.Errorf("Error: parser.go does not contain ParseTokens")
+			}
+			if bytes.Contains(file.Content, []byte("func (p *Parser) ParseString(")) {
+				t.Errorf("Error: parser.go contains ParseString without a lexer")
+			}
+		}
+	}
+}
+
+func TestGenerateWithPrefix(t *testing.T) {
+	result, err := GenerateWithOptions(Options{
+		LexerFile:   writeSpec(t, "test.l", testLexer),
+		GrammarFile: writeSpec(t, "test.g", testGrammar),
+		PackageName: "test",
+		Prefix:      "expr",
+	})
+
+	if err != nil {
+		t.Fatalf("Error: %s", err.Error())
+	}
+
+	for _, file := range result.Files {
+		if !strings.HasPrefix(file.Name, "expr_") {
+			t.Errorf("Error: the name of %s is not prefixed", file.Name)
+		}
+
+		fileAst, err := parser.ParseFile(token.NewFileSet(), file.Name, file.Content, parser.ParseComments)
+		if err != nil {
+			t.Fatalf("Error: %s", err.Error())
+		}
+		for name := range fileAst.Scope.Objects {
+			if !strings.HasPrefix(name, "expr") && !strings.HasPrefix(name, "Expr") {
+				t.Errorf("Error: %s declares %s, which is not prefixed", file.Name, name)
+			}
+		}
+
+		//The doc comments start with the prefixed names, as in "ExprParser parses..."
+		for _, decl := range fileAst.Decls {
+			var name string
+			var doc *ast.CommentGroup
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				if decl.Recv == nil {
+					name, doc = decl.Name.Name, decl.Doc
+				}
+			case *ast.GenDecl:
+				if typeSpec, ok := decl.Specs[0].(*ast.TypeSpec); ok && len(decl.Specs) == 1 {
+					name, doc = typeSpec.Name.Name, decl.Doc
+				}
+			}
+			if doc == nil {
+				continue
+			}
+			if words := strings.Fields(doc.Text()); len(words) > 0 && words[0] != name && strings.HasSuffix(name, words[0]) {
+				t.Errorf("Error: the doc comment of %s in %s starts with %s", name, file.Name, words[0])
+			}
+		}
+	}
+}
+
+func TestRenameCode(t *testing.T) {
+	p := &prefixer{"expr", map[string]bool{"NUMBER": true, "symbol": true, "Token": true, "function": true}}
+
+	code := `/*
+function is the semantic function, which sets the Token of a symbol.
+*/
+func function(genSym *symbol, t Token) {
+	*genSym = symbol{Token: NUMBER}
+	switch genSym.Token {
+	case NUMBER:
+	}
+	m := map[Token]int{NUMBER: 1}
+}
+
+//Token is not renamed in this comment, which documents another declaration
+type tokens []Token`
+	expected := `/*
+exprFunction is the semantic function, which sets the Token of a symbol.
+*/
+func exprFunction(genSym *exprSymbol, t ExprToken) {
+	*genSym = exprSymbol{Token: ExprNUMBER}
+	switch genSym.Token {
+	case ExprNUMBER:
+	}
+	m := map[ExprToken]int{ExprNUMBER: 1}
+}
+
+//Token is not renamed in this comment, which documents another declaration
+type tokens []ExprToken`
+
+	if result := string(p.RenameCode([]byte(code))); result != expected {
+		t.Errorf("Error: the renamed code is\n%s\nshould be\n%s", result, expected)
+	}
+}
+
+func TestGenerateIsReproducible(t *testing.T) {
+	opts := Options{
+		LexerFile:   writeSpec(t, "test.l", testLexer),
+		GrammarFile: writeSpec(t, "test.g", testGrammar),
+		PackageName: "test",
+	}
+
+	firstResult, err := GenerateWithOptions(opts)
+	if err != nil {
+		t.Fatalf("Error: %s", err.Error())
+	}
+	firstFiles := firstResult.Files
+
+	for i := 0; i < 5; i++ {
+		result, err := GenerateWithOptions(opts)
+		if err != nil {
+			t.Fatalf("Error: %s", err.Error())
+		}
+		files := result.Files
+		if len(files) != len(firstFiles) {
+			t.Fatalf("Error: %d files were generated, should be %d", len(files), len(firstFiles))
+		}
+		for j, file := range files {
+			if file.Name != firstFiles[j].Name || !bytes.Equal(file.Content, firstFiles[j].Content) {
+				t.Errorf("Error: %s differs between two runs", file.Name)
+			}
+		}
+	}
+
+	for _, file := range firstFiles {
+		if !bytes.HasPrefix(file.Content, []byte("// Code generated by papageno from test.l and test.g. DO NOT EDIT.\n")) {
+			t.Errorf("Error: %s does not start with the generated code header", file.Name)
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	opts := Options{
+		LexerFile:   writeSpec(t, "test.l", testLexer),
+		GrammarFile: writeSpec(t, "test.g", testGrammar),
+		OutDir:      filepath.Join(t.TempDir(), "test"),
+	}
+
+	if _, err := GenerateWithOptions(opts); err != nil {
+		t.Fatalf("Error: %s", err.Error())
+	}
+
+	staleFiles, err := Verify(opts)
+	if err != nil {
+		t.Fatalf("Error: %s", err.Error())
+	}
+	if len(staleFiles) != 0 {
+		t.Errorf("Error: %d files are stale right after the generation", len(staleFiles))
+	}
+
+	tokensPath := filepath.Join(opts.OutDir, "tokens.go")
+	content, err := os.ReadFile(tokensPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content = bytes.Replace(content, []byte("package test\n"), []byte("package test\n\n// Edited by hand\n"), 1)
+	if err := os.WriteFile(tokensPath, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(opts.OutDir, "rules.go")); err != nil {
+		t.Fatal(err)
+	}
+
+	staleFiles, err = Verify(opts)
+	if err != nil {
+		t.Fatalf("Error: %s", err.Error())
+	}
+	if len(staleFiles) != 2 {
+		t.Fatalf("Error: %d files are stale, should be 2", len(staleFiles))
+	}
+	for _, staleFile := range staleFiles {
+		switch staleFile.Name {
+		case "tokens.go":
+			if !strings.Contains(staleFile.Diff, "\n@@ -2,8 +2,6 @@\n") || !strings.Contains(staleFile.Diff, "\n-// Edited by hand\n") {
+				t.Errorf("Error: unexpected diff for tokens.go:\n%s", staleFile.Diff)
+			}
+		case "rules.go":
+			if !strings.Contains(staleFile.Diff, "(missing)") || !strings.Contains(staleFile.Diff, "\n+package test\n") {
+				t.Errorf("Error: unexpected diff for rules.go:\n%s", staleFile.Diff)
+			}
+		default:
+			t.Errorf("Error: %s should not be stale", staleFile.Name)
+		}
+	}
+}
+
+func TestGenerateWithOptionsErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		lexer   string
+		grammar string
+		target  interface{}
+	}{
+		{"missing definition", "%%\n{NUMBER}\n{\n}\n%%\n", testGrammar, new(*MissingDefinitionError)},
+		{"regex parse failure", "%%\n(a\n{\n}\n%%\n", testGrammar, new(*RegexParseError)},
+		{"missing axiom", testLexer, "%%\n%%\nE : NUMBER\n{\n};\n", new(*MissingAxiomError)},
+		{"unused axiom", testLexer, "%%\n%axiom S\n%%\nE : NUMBER\n{\n};\n", new(*MissingAxiomError)},
+		{"operator form", testLexer, "%%\n%axiom E\n%%\nE : E E\n{\n} | NUMBER\n{\n};\n", new(*OperatorFormError)},
+		{"precedence conflict", testLexer, "%%\n%axiom E\n%%\nE : E PLUS E\n{\n} | NUMBER\n{\n};\n", new(*PrecedenceConflictError)},
+		{"syntax error", testLexer, "%%\n%axiom E\n%%\nE NUMBER\n{\n};\n", new(*SyntaxError)},
+		{"missing nested definition", "A {B}\n%%\n{A}\n{\n}\n%%\n", testGrammar, new(*MissingDefinitionError)},
+		{"definition cycle", "A x{B}\nB {C}y\nC {A}\n%%\n{A}\n{\n}\n%%\n", testGrammar, new(*DefinitionCycleError)},
+		{"undeclared start condition", "%x A\n%%\n<B>a\n{\n}\n%%\n", testGrammar, new(*StartConditionError)},
+		{"undeclared BEGIN", "%%\na\n{\n\tBEGIN(A)\n}\n%%\n", testGrammar, new(*StartConditionError)},
+		{"start condition without rules", "%x A\n%%\na\n{\n}\n%%\n", testGrammar, new(*StartConditionError)},
+		{"unknown option", "%option nothing\n%%\na\n{\n}\n%%\n", testGrammar, new(*SyntaxError)},
+	}
+
+	for _, test := range tests {
+		_, err := GenerateWithOptions(Options{
+			LexerFile:   writeSpec(t, "test.l", test.lexer),
+			GrammarFile: writeSpec(t, "test.g", test.grammar),
+			PackageName: "test",
+		})
+
+		if err == nil {
+			t.Errorf("Error: %s: expected an error", test.name)
+		} else if !errors.As(err, test.target) {
+			t.Errorf("Error: %s: unexpected error %T: %s", test.name, err, err.Error())
+		}
+	}
+
+	if _, err := GenerateWithOptions(Options{LexerFile: writeSpec(t, "test.l", "%%\n%%\n"), GrammarFile: writeSpec(t, "test.g", testGrammar), PackageName: "test"}); err != ErrNoLexRules {
+		t.Errorf("Error: expected ErrNoLexRules, got %v", err)
+	}
+}
+
+func TestInterpreter(t *testing.T) {
+	lexerFile := writeSpec(t, "test.l", testLexer)
+	grammarFile := writeSpec(t, "test.g", testGrammar)
+
+	in, err := NewInterpreter(lexerFile, grammarFile, InterpreterActions{
+		Tokens: map[string]TokenAction{
+			"NUMBER": func(text string) (interface{}, error) {
+				return strconv.Atoi(text)
+			},
+		},
+		Rules: map[string]RuleAction{
+			"E : E PLUS NUMBER": func(lhs *papageno.Symbol, rhs []*papageno.Symbol) {
+				lhs.Value = rhs[0].Value.(int) + rhs[2].Value.(int)
+			},
+			"E:NUMBER": func(lhs *papageno.Symbol, rhs []*papageno.Symbol) {
+				lhs.Value = rhs[0].Value
+			},
+		},
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, numThreads := range []int{1, 2} {
+		root, err := in.ParseString([]byte("1+2+30"), numThreads)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+		if root.Value != 33 {
+			t.Errorf("Error: expected 33 with %d threads, got %v", numThreads, root.Value)
+		}
+	}
+
+	//Without actions the values of the terminals are the matched text and the tree is still built
+	in, err = NewInterpreter(lexerFile, grammarFile, InterpreterActions{})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root, err := in.ParseString([]byte("1+2"), 1)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sb strings.Builder
+	root.PrintTree(&sb, in.TokenString)
+	if expected := ">  E\n>    E\n>      NUMBER\n>    PLUS\n>    NUMBER\n"; sb.String() != expected {
+		t.Errorf("Error: unexpected tree:\n%s", sb.String())
+	}
+
+	number, _ := in.Token("NUMBER")
+	if child := root.Child.Next.Next; child.Token != number || child.Value != "2" {
+		t.Errorf("Error: unexpected symbol %s %v", in.TokenString(child.Token), child.Value)
+	}
+
+	//The parser of the interpreter keeps its pools between the parses, until Release
+	if _, err := in.ParseString([]byte("1"+strings.Repeat("+1", 100000)), 1); err != nil {
+		t.Fatal(err)
+	}
+	largeSize := in.Stats.StackPoolSizes[0]
+
+	sizes := make([]int, 0, 2)
+	for _, clearPools := range []func(){in.Reset, in.Release} {
+		clearPools()
+		if _, err := in.ParseString([]byte("1+2"), 1); err != nil {
+			t.Fatal(err)
+		}
+		sizes = append(sizes, in.Stats.StackPoolSizes[0])
+	}
+	if sizes[0] < largeSize || sizes[1] >= sizes[0] {
+		t.Errorf("Error: the stack pool has %d stacks after a large parse, then %v, it should be kept by Reset and dropped by Release", largeSize, sizes)
+	}
+}
+
+func TestInterpreterErrors(t *testing.T) {
+	lexerFile := writeSpec(t, "test.l", testLexer)
+	grammarFile := writeSpec(t, "test.g", testGrammar)
+
+	var actionErr *ActionError
+
+	_, err := NewInterpreter(lexerFile, grammarFile, InterpreterActions{Rules: map[string]RuleAction{"E : PLUS": nil}})
+	if !errors.As(err, &actionErr) {
+		t.Errorf("Error: expected an ActionError for an unknown rule, got %v", err)
+	}
+
+	_, err = NewInterpreter(lexerFile, grammarFile, InterpreterActions{Tokens: map[string]TokenAction{"MINUS": nil}})
+	if !errors.As(err, &actionErr) {
+		t.Errorf("Error: expected an ActionError for an unknown terminal, got %v", err)
+	}
+
+	var inferenceErr *TokenInferenceError
+
+	ambiguousLexer := writeSpec(t, "ambiguous.l", "%%\n[0-9]+\n{\n\tif yytext == \"0\" {\n\t\t*genSym = symbol{Token: PLUS}\n\t}\n\t*genSym = symbol{Token: NUMBER}\n\treturn _LEX_CORRECT\n}\n%%\n")
+	_, err = NewInterpreter(ambiguousLexer, grammarFile, InterpreterActions{})
+	if !errors.As(err, &inferenceErr) || len(inferenceErr.Terminals) != 2 {
+		t.Errorf("Error: expected a TokenInferenceError, got %v", err)
+	}
+}
+
+func TestWarningsAndLogging(t *testing.T) {
+	grammar := strings.Replace(testGrammar, "%axiom E", "%axiom E\n%axiom E", 1)
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	warnings, err := CheckWithOptions(Options{
+		LexerFile:   writeSpec(t, "test.l", testLexer),
+		GrammarFile: writeSpec(t, "test.g", grammar),
+		Logger:      logger,
+	})
+
+	if err != nil {
+		t.Fatalf("Error: %s", err.Error())
+	}
+
+	if len(warnings) != 1 || filepath.Base(warnings[0].Filename) != "test.g" || warnings[0].Msg != "the axiom is defined more than once" {
+		t.Errorf("Error: unexpected warnings %v", warnings)
+	}
+
+	for _, expected := range []string{"level=WARN", `msg="precedence matrix"`, `msg="new rules"`} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("Error: the log does not contain %s", expected)
+		}
+	}
+}
+
+func TestUnreachableLexRules(t *testing.T) {
+	lexer := strings.Replace(testLexer, "\\+\n", "12\n{\n\t*genSym = symbol{Token: NUMBER}\n\treturn _LEX_CORRECT\n}\n\\+\n", 1)
+	lexerFile := writeSpec(t, "test.l", lexer)
+	grammarFile := writeSpec(t, "test.g", testGrammar)
+
+	warnings, err := CheckWithOptions(Options{LexerFile: lexerFile, GrammarFile: grammarFile})
+
+	if err != nil {
+		t.Fatalf("Error: %s", err.Error())
+	}
+
+	expected := `the lexer rule can never match, since the strings it matches, such as "12", are matched by the earlier rule at line 7`
+	if len(warnings) != 1 || warnings[0].Line != 12 || warnings[0].Msg != expected {
+		t.Errorf("Error: unexpected warnings %v", warnings)
+	}
+
+	var buf bytes.Buffer
+	if err := ExplainWithOptions(Options{LexerFile: lexerFile, GrammarFile: grammarFile}, &buf); err != nil {
+		t.Fatalf("Error: %s", err.Error())
+	}
+	if !strings.Contains(buf.String(), `line 7 and line 12: "12"`) {
+		t.Errorf("Error: the overlapping rules are not explained:\n%s", buf.String())
+	}
+
+	_, err = CheckWithOptions(Options{LexerFile: lexerFile, GrammarFile: grammarFile, WarningsAsErrors: true})
+
+	var warningErr *WarningError
+	if !errors.As(err, &warningErr) || !strings.Contains(err.Error(), "test.l:12:1: the lexer rule can never match") {
+		t.Errorf("Error: unexpected error %v", err)
+	}
+
+	//The keywords before the identifiers are not reported
+	lexer = strings.Replace(testLexer, "{DIGIT}+\n", "12\n{\n\t*genSym = symbol{Token: NUMBER}\n\treturn _LEX_CORRECT\n}\n{DIGIT}+\n", 1)
+	if _, err := CheckWithOptions(Options{LexerFile: writeSpec(t, "test.l", lexer), GrammarFile: grammarFile, WarningsAsErrors: true}); err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+}
+
+func TestStartConditions(t *testing.T) {
+	lexer := `%cut \n
+
+%x COMMENT
+
+%%
+
+[0-9]+
+{
+	*genSym = symbol{Token: NUMBER}
+	return _LEX_CORRECT
+}
+\+
+{
+	*genSym = symbol{Token: PLUS}
+	return _LEX_CORRECT
+}
+<INITIAL>/\*
+{
+	BEGIN(COMMENT)
+	return _SKIP
+}
+<COMMENT>\*/
+{
+	BEGIN(INITIAL)
+	return _SKIP
+}
+<COMMENT>[^\*]+|\*
+{
+	return _SKIP
+}
+
+%%
+func lexerPreallocMem(ctx *lexerContext, inputSize int, numThreads int) {
+}
+`
+
+	lexerFile := writeSpec(t, "test.l", lexer)
+	grammarFile := writeSpec(t, "test.g", testGrammar)
+
+	result, err := GenerateWithOptions(Options{LexerFile: lexerFile, GrammarFile: grammarFile, PackageName: "test"})
+
+	if err != nil {
+		t.Fatalf("Error: %s", err.Error())
+	}
+
+	for _, file := range result.Files {
+		if file.Name == "lexerfunction.go" && !bytes.Contains(file.Content, []byte("*condition = 1 /* COMMENT */")) {
+			t.Errorf("Error: BEGIN(COMMENT) was not rewritten:\n%s", file.Content)
+		}
+	}
+
+	in, err := NewInterpreter(lexerFile, grammarFile, InterpreterActions{})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root, err := in.ParseString([]byte("1+/* 2+3 * 4 */5"), 1)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sb strings.Builder
+	root.PrintTree(&sb, in.TokenString)
+	if expected := ">  E\n>    E\n>      NUMBER\n>    PLUS\n>    NUMBER\n"; sb.String() != expected {
+		t.Errorf("Error: unexpected tree:\n%s", sb.String())
+	}
+
+	//The rules with a prefix are not active in INITIAL
+	if _, err := in.ParseString([]byte("1+*/5"), 1); err == nil {
+		t.Errorf("Error: expected a lexing error")
+	}
+}
+
+func TestCountedRepetitionInLexer(t *testing.T) {
+	lexer := strings.Replace(testLexer, "{DIGIT}+", "{DIGIT}{2,3}", 1)
+
+	in, err := NewInterpreter(writeSpec(t, "test.l", lexer), writeSpec(t, "test.g", testGrammar), InterpreterActions{})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := in.ParseString([]byte("12+345"), 1); err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+	if _, err := in.ParseString([]byte("1+345"), 1); err == nil {
+		t.Errorf("Error: a number with one digit should not be lexed")
+	}
+
+	//A quantifier after a definition applies to all of it, not only to its last character.
+	//The ? of the specifications is an ordinary char, so {0,1} makes the definition optional
+	number := "\n{\n\t*genSym = symbol{Token: NUMBER}\n\treturn _LEX_CORRECT\n}\n"
+	lexer = "AB ab\n%%\n{AB}{2}" + number + "x{AB}*y" + number + "z{AB}{0,1}w" + number +
+		"\\+\n{\n\t*genSym = symbol{Token: PLUS}\n\treturn _LEX_CORRECT\n}\n%%\n"
+
+	in, err = NewInterpreter(writeSpec(t, "test.l", lexer), writeSpec(t, "test.g", testGrammar), InterpreterActions{})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := in.ParseString([]byte("abab+xy+xababy+zw+zabw"), 1); err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+	for _, str := range []string{"abb", "xabby", "zaw"} {
+		if _, err := in.ParseString([]byte(str), 1); err == nil {
+			t.Errorf("Error: %s should not be lexed", str)
+		}
+	}
+}
+
+func TestUnicodeLexer(t *testing.T) {
+	lexer := strings.Replace(testLexer, "DIGIT [0-9]", "%option utf8\nDIGIT [0-9]\nWORD \\p{L}[\\p{L}\\p{N}]*", 1)
+	lexer = strings.Replace(lexer, "{DIGIT}+", "{DIGIT}+|{WORD}|\\u{1F600}", 1)
+
+	in, err := NewInterpreter(writeSpec(t, "test.l", lexer), writeSpec(t, "test.g", testGrammar), InterpreterActions{})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root, err := in.ParseString([]byte("été+日本2+1+😀"), 1)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if child := root.Child; child.Child.Child.Child.Value != "été" {
+		t.Errorf("Error: unexpected value %v", child.Child.Child.Child.Value)
+	}
+}
+
+func TestLexerDfaClasses(t *testing.T) {
+	result, err := regex.ParseString([]byte("[a-z]+|[0-9]+|\\+"), 1)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dfa := result.Value.(*regex.Nfa).ToDfa()
+	automaton := lexerDfa(dfa)
+
+	//The letters, the digits, the plus and every other byte
+	if automaton.NumClasses != 4 {
+		t.Errorf("Error: the automaton has %d classes, should be 4", automaton.NumClasses)
+	}
+	if len(automaton.Transitions) != dfa.NumStates*automaton.NumClasses {
+		t.Errorf("Error: the table has %d transitions, should be %d", len(automaton.Transitions), dfa.NumStates*automaton.NumClasses)
+	}
+
+	for _, state := range dfa.GetStates() {
+		for c := 0; c < 256; c++ {
+			expected := -1
+			if state.Transitions[c] != nil {
+				expected = state.Transitions[c].Num
+			}
+			if next := automaton.Next(state.Num, byte(c)); next != expected {
+				t.Errorf("Error: state %d reading %d goes to %d, should go to %d", state.Num, c, next, expected)
+			}
+		}
+	}
+}
+
+func TestManyKeywords(t *testing.T) {
+	const numKeywords = 500
+
+	in, err := NewInterpreter(writeSpec(t, "test.l", manyKeywordsLexer(numKeywords)), writeSpec(t, "test.g", testGrammar), InterpreterActions{})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	str := keyword(0) + "+" + keyword(numKeywords-1) + "+" + keyword(numKeywords-1) + "x"
+	if _, err := in.ParseString([]byte(str), 1); err != nil {
+		t.Errorf("Error: %s", err.Error())
+	}
+}
+
+/*
+BenchmarkManyKeywords measures the time spent building a lexer with many keywords that share their prefixes.
+*/
+func BenchmarkManyKeywords(b *testing.B) {
+	lexerFile := writeSpec(b, "test.l", manyKeywordsLexer(500))
+	grammarFile := writeSpec(b, "test.g", testGrammar)
+
+	for i := 0; i < b.N; i++ {
+		if _, err := NewInterpreter(lexerFile, grammarFile, InterpreterActions{}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+/*
+BenchmarkLexManyKeywords parses 1MB of keywords chosen among 3000, whose automaton has thousands of states,
+reporting the time spent lexing besides the total time of each parse.
+*/
+func BenchmarkLexManyKeywords(b *testing.B) {
+	const numKeywords = 3000
+
+	in, err := NewInterpreter(writeSpec(b, "test.l", manyKeywordsLexer(numKeywords)), writeSpec(b, "test.g", testGrammar), InterpreterActions{})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	var sb strings.Builder
+	for i := 0; sb.Len() < 1<<20; i++ {
+		if i > 0 {
+			sb.WriteString("+")
+		}
+		sb.WriteString(keyword((i * 7919) % numKeywords))
+	}
+	data := []byte(sb.String())
+
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+
+	var lexTime int64
+	for i := 0; i < b.N; i++ {
+		if _, err := in.ParseString(data, 1); err != nil {
+			b.Fatal(err)
+		}
+		lexTime += in.Stats.LexTimeTotal.Nanoseconds()
+	}
+	b.ReportMetric(float64(lexTime)/float64(b.N), "lex-ns/op")
+}
+
+/*
+manyKeywordsLexer returns a lexer with numKeywords keywords with shared prefixes,
+followed by an identifier rule that matches all of them.
+*/
+func manyKeywordsLexer(numKeywords int) string {
+	var sb strings.Builder
+	sb.WriteString("%%\n\n")
+	for i := 0; i < numKeywords; i++ {
+		sb.WriteString(fmt.Sprintf("%s\n{\n\t*genSym = symbol{Token: NUMBER}\n\treturn _LEX_CORRECT\n}\n", keyword(i)))
+	}
+	sb.WriteString("[a-z]+\n{\n\t*genSym = symbol{Token: NUMBER}\n\treturn _LEX_CORRECT\n}\n")
+	sb.WriteString("\\+\n{\n\t*genSym = symbol{Token: PLUS}\n\treturn _LEX_CORRECT\n}\n")
+	sb.WriteString("%%\n")
+	return sb.String()
+}
+
+/*
+keyword returns the i-th keyword of TestManyKeywords, a word of lowercase letters.
+*/
+func keyword(i int) string {
+	word := []byte("kw")
+	for ; i > 0; i /= 3 {
+		word = append(word, byte('a'+i%3))
+	}
+	return string(append(word, 'z'))
+}
+
+func TestSymbolPositions(t *testing.T) {
+	lexer := strings.Replace(testLexer, "\\+\n", "[ \\n]+\n{\n\treturn _SKIP\n}\n\\+\n", 1)
+
+	in, err := NewInterpreter(writeSpec(t, "test.l", lexer), writeSpec(t, "test.g", testGrammar), InterpreterActions{})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	str := []byte("12 + 3\n+ 456\n+ 7\n")
+
+	for _, numThreads := range []int{1, 2} {
+		root, err := in.ParseString(str, numThreads)
+
+		if err != nil {
+			t.Fatalf("Error: %s", err.Error())
+		}
+
+		//The root ends with the last number, without the skipped newline
+		if root.Offset != 0 || root.Length != 16 {
+			t.Errorf("Error: the root spans %d bytes from %d, should span 16 bytes from 0", root.Length, root.Offset)
+		}
+
+		//Collect the numbers, which are the leaves of the tree
+		var numbers []*papageno.Symbol
+		var walk func(sym *papageno.Symbol)
+		walk = func(sym *papageno.Symbol) {
+			for ; sym != nil; sym = sym.Next {
+				if sym.Child == nil && sym.Value != nil && sym.Value != "+" {
+					numbers = append(numbers, sym)
+				}
+				walk(sym.Child)
+			}
+		}
+		walk(root)
+
+		index := papageno.NewLineIndex(str)
+		expected := []struct {
+			offset, length, line, column int
+		}{{0, 2, 1, 1}, {5, 1, 1, 6}, {9, 3, 2, 3}, {15, 1, 3, 3}}
+
+		if len(numbers) != len(expected) {
+			t.Fatalf("Error: %d numbers were parsed, should be %d", len(numbers), len(expected))
+		}
+		for i, e := range expected {
+			sym := numbers[i]
+			line, column := index.Position(sym.Offset)
+			if sym.Offset != e.offset || sym.Length != e.length || line != e.line || column != e.column {
+				t.Errorf("Error: %v (%d threads) is at %d:%d, offset %d, length %d, should be at %d:%d, offset %d, length %d",
+					sym.Value, numThreads, line, column, sym.Offset, sym.Length, e.line, e.column, e.offset, e.length)
+			}
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	lexer := "%cut \\n\n%%\n[ \\n]+\n{\n\treturn _SKIP\n}\n[0-9]+\n{\n\t*genSym = symbol{Token: NUMBER}\n\treturn _LEX_CORRECT\n}\n" +
+		"\\(\n{\n\t*genSym = symbol{Token: LPAR}\n\treturn _LEX_CORRECT\n}\n\\)\n{\n\t*genSym = symbol{Token: RPAR}\n\treturn _LEX_CORRECT\n}\n%%\n"
+	grammar := "%%\n%axiom E\n%%\nE : LPAR E RPAR\n{\n} | NUMBER\n{\n};\n"
+
+	in, err := NewInterpreter(writeSpec(t, "paren.l", lexer), writeSpec(t, "paren.g", grammar), InterpreterActions{})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		input      string
+		numThreads int
+		expected   papageno.ParseError
+	}{
+		//There is no precedence relation between two numbers
+		{"(1\n2)", 1, papageno.ParseError{Phase: papageno.PhaseParsing, Offset: 3, Line: 2, Column: 1, Token: "NUMBER", StackTop: "NUMBER", Expected: []string{"RPAR", "_TERM"}}},
+		//The end of the input is reached with an open parenthesis
+		{"(1\n", 1, papageno.ParseError{Phase: papageno.PhaseParsing, Offset: 3, Line: 2, Column: 1, Token: "_TERM", StackTop: "LPAR", Handle: []string{"LPAR", "E"}}},
+		//Each thread can parse its part, the final pass finds the extra parenthesis
+		{"(  \n1))", 2, papageno.ParseError{Phase: papageno.PhaseFinalPass, Offset: 7, Line: 2, Column: 4, Token: "_TERM", StackTop: "RPAR", Handle: []string{"E", "RPAR"}}},
+		{"(1\n?)", 2, papageno.ParseError{Phase: papageno.PhaseLexing, Offset: 3, Line: 2, Column: 1}},
+		//The second lexing thread, or the first one, finds no tokens
+		{"1)\n", 2, papageno.ParseError{Phase: papageno.PhaseParsing, Offset: 3, Line: 2, Column: 1, Token: "_TERM", StackTop: "RPAR", Handle: []string{"E", "RPAR"}}},
+		{"\n\n\n(1", 2, papageno.ParseError{Phase: papageno.PhaseParsing, Offset: 5, Line: 4, Column: 3, Token: "_TERM", StackTop: "LPAR", Handle: []string{"LPAR", "E"}}},
+	}
+
+	for _, test := range tests {
+		_, err := in.ParseString([]byte(test.input), test.numThreads)
+
+		var parseErr *papageno.ParseError
+		if !errors.As(err, &parseErr) {
+			t.Errorf("Error: expected a ParseError for %q, got %v", test.input, err)
+			continue
+		}
+
+		if fmt.Sprint(*parseErr) != fmt.Sprint(test.expected) {
+			t.Errorf("Error: wrong error for %q (%s)\n%+v\nshould be\n%+v", test.input, parseErr.Error(), *parseErr, test.expected)
+		}
+	}
+}
+
+/*
+TestLexingAtEndOfInput checks the tokens that end together with the input: the lexer backs off to the last final state
+instead of dropping the token, and the trailing text that matches no rule is an error instead of being ignored.
+*/
+func TestLexingAtEndOfInput(t *testing.T) {
+	lexer := "%%\n[ \\n]+\n{\n\treturn _SKIP\n}\n[0-9]+(\\.[0-9]+){0,1}\n{\n\t*genSym = symbol{Token: NUMBER}\n\treturn _LEX_CORRECT\n}\n" +
+		"\\.\n{\n\treturn _SKIP\n}\n<[a-z]+>\n{\n\treturn _SKIP\n}\n%%\n"
+	grammar := "%%\n%axiom E\n%%\nE : NUMBER\n{\n};\n"
+
+	in, err := NewInterpreter(writeSpec(t, "number.l", lexer), writeSpec(t, "number.g", grammar), InterpreterActions{})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//The automaton reads 1. looking for a fraction, then backs off to 1 and lexes the dot by itself
+	for _, input := range []string{"1.", "1.5", "1.<ab>", "1.5."} {
+		if _, err := in.ParseString([]byte(input), 1); err != nil {
+			t.Errorf("Error: %q should be parsed, got %v", input, err)
+		}
+	}
+
+	//The text of an unterminated tag reaches the end of the input without a final state
+	tests := []struct {
+		input  string
+		offset int
+	}{
+		{"1<ab", 1},
+		{"1.<", 2},
+	}
+
+	for _, test := range tests {
+		_, err := in.ParseString([]byte(test.input), 1)
+
+		var parseErr *papageno.ParseError
+		if !errors.As(err, &parseErr) || parseErr.Phase != papageno.PhaseLexing || parseErr.Offset != test.offset {
+			t.Errorf("Error: expected a lexing error at offset %d for %q, got %v", test.offset, test.input, err)
+		}
+	}
+}
+
+func TestErrorRecovery(t *testing.T) {
+	lexer := "%cut \\n\n%%\n[ \\n]+\n{\n\treturn _SKIP\n}\n[0-9]+\n{\n\t*genSym = symbol{Token: NUMBER}\n\treturn _LEX_CORRECT\n}\n" +
+		"\\(\n{\n\t*genSym = symbol{Token: LPAR}\n\treturn _LEX_CORRECT\n}\n\\)\n{\n\t*genSym = symbol{Token: RPAR}\n\treturn _LEX_CORRECT\n}\n" +
+		"\\+\n{\n\t*genSym = symbol{Token: PLUS}\n\treturn _LEX_CORRECT\n}\n%%\n"
+	grammar := "%%\n%axiom E\n%sync RPAR PLUS\n%%\nE : E PLUS T\n{\n} | T\n{\n};\nT : LPAR E RPAR\n{\n} | NUMBER\n{\n};\n"
+
+	lexerFile := writeSpec(t, "paren.l", lexer)
+	in, err := NewInterpreter(lexerFile, writeSpec(t, "paren.g", grammar), InterpreterActions{})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	countErrorNodes := func(sym *papageno.Symbol) int {
+		count := 0
+		var visit func(sym *papageno.Symbol)
+		visit = func(sym *papageno.Symbol) {
+			for ; sym != nil; sym = sym.Next {
+				if sym.Token == papageno.ErrorToken {
+					count++
+				}
+				visit(sym.Child)
+			}
+		}
+		visit(sym)
+		return count
+	}
+
+	for _, numThreads := range []int{1, 2} {
+		//Both parenthesized expressions contain an error, the parsing resumes at the closing parenthesis
+		root, err := in.ParseString([]byte("(1 2)+\n(3 4)+5"), numThreads)
+
+		var errs papageno.ParseErrors
+		if !errors.As(err, &errs) {
+			t.Fatalf("Error: expected ParseErrors with %d threads, got %v", numThreads, err)
+		}
+		if len(errs) != 2 || errs[0].Offset != 3 || errs[1].Offset != 10 || errs[1].Line != 2 || errs[1].Column != 4 {
+			t.Errorf("Error: unexpected errors with %d threads:\n%v", numThreads, err)
+		}
+
+		if root == nil {
+			t.Fatalf("Error: expected a partial tree with %d threads", numThreads)
+		}
+		if root.Offset != 0 || root.Length != 14 || countErrorNodes(root) == 0 {
+			t.Errorf("Error: unexpected partial tree with %d threads: %s at %d with length %d", numThreads, in.TokenString(root.Token), root.Offset, root.Length)
+		}
+		if name := in.TokenString(papageno.ErrorToken); name != "_ERROR" {
+			t.Errorf("Error: the error nodes are named %s, should be _ERROR", name)
+		}
+
+		//The errors of the lexer are collected as well
+		_, err = in.ParseString([]byte("1+?+2"), numThreads)
+
+		if !errors.As(err, &errs) || errs[0].Phase != papageno.PhaseLexing {
+			t.Errorf("Error: expected a lexing error first with %d threads, got %v", numThreads, err)
+		}
+
+		root, err = in.ParseString([]byte("(1+2)+\n3"), numThreads)
+
+		if err != nil || countErrorNodes(root) != 0 {
+			t.Errorf("Error: unexpected error nodes or error with %d threads: %v", numThreads, err)
+		}
+
+		//The error node pushed after the closing parenthesis makes a handle longer than any rhs
+		root, err = in.ParseString([]byte("(22)221?22 "), numThreads)
+
+		if !errors.As(err, &errs) || len(errs) != 3 || errs[2].Handle == nil {
+			t.Errorf("Error: expected an error for the long handle with %d threads, got %v", numThreads, err)
+		}
+		if root == nil || countErrorNodes(root) == 0 {
+			t.Errorf("Error: expected a partial tree with %d threads", numThreads)
+		}
+	}
+
+	_, err = NewInterpreter(lexerFile, writeSpec(t, "wrong.g", strings.Replace(grammar, "PLUS\n", "PLUS T\n", 1)), InterpreterActions{})
+
+	var syncErr *SyncTerminalError
+	if !errors.As(err, &syncErr) || syncErr.Name != "T" {
+		t.Errorf("Error: expected a SyncTerminalError for T, got %v", err)
+	}
+}
+
+func TestExpandDefinitions(t *testing.T) {
+	definitions, err := expandDefinitions(map[string]string{
+		"ATTR":   "{IDENT}{EQUALS}{VALUE}",
+		"IDENT":  "[a-z]{DIGIT}*",
+		"EQUALS": "=",
+		"VALUE":  "\"{IDENT}\"",
+		"DIGIT":  "[0-9]",
+		"H2":     "<h2>{ATTR}",
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := `<h2>(([a-z]([0-9])*)(=)("([a-z]([0-9])*)"))`; definitions["H2"] != expected {
+		t.Errorf("Error: H2 was expanded to %s, should be %s", definitions["H2"], expected)
+	}
+
+	//Each expansion is grouped, so that an alternation is not split by the text around it
+	definitions, err = expandDefinitions(map[string]string{"X": "a|b", "Y": "{X}c"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "(a|b)c"; definitions["Y"] != expected {
+		t.Errorf("Error: Y was expanded to %s, should be %s", definitions["Y"], expected)
+	}
+
+	_, err = expandDefinitions(map[string]string{"A": "x{B}", "B": "{C}y", "C": "{A}", "D": "{A}"})
+
+	var cycleErr *DefinitionCycleError
+	if !errors.As(err, &cycleErr) {
+		t.Fatalf("Error: expected a DefinitionCycleError, got %v", err)
+	}
+	if cycle := strings.Join(cycleErr.Cycle, " -> "); cycle != "A -> B -> C -> A" {
+		t.Errorf("Error: unexpected cycle %s", cycle)
+	}
+}
+
+func TestSyntaxErrorPositions(t *testing.T) {
+	grammar := "%%\n%axiom E\n%%\n\nE : E PLUS NUMBER\n{\n} | NUMBER\n{\n};\n\nF NUMBER\n{\n};\n\nG : NUMBER\n{\n} | PLUS { ;\n"
+	lexer := "DIGIT [0-9]\n%%\n{DIGIT}+\n{\n}\n\n{NUMBER}\n{\n}\n\n(a\n{\n}\n%%\n"
+
+	lexerFile := writeSpec(t, "test.l", lexer)
+	grammarFile := writeSpec(t, "test.g", grammar)
+
+	_, err := GenerateWithOptions(Options{LexerFile: lexerFile, GrammarFile: grammarFile, PackageName: "test"})
+
+	var errs ErrorList
+	if !errors.As(err, &errs) {
+		t.Fatalf("Error: expected an ErrorList, got %v", err)
+	}
+
+	expected := []string{
+		lexerFile + `:7:1: missing definition "NUMBER"`,
+		grammarFile + ":11:3: expected ':' after F, found 'N'",
+		grammarFile + ":17:10: the semantic action of a rule for G is missing a closing brace",
+	}
+
+	if len(errs) != len(expected) {
+		t.Fatalf("Error: expected %d errors, got:\n%s", len(expected), err.Error())
+	}
+	for i, e := range errs {
+		if e.Error() != expected[i] {
+			t.Errorf("Error: error %d is %q, should be %q", i, e.Error(), expected[i])
+		}
+	}
+
+	var missingErr *MissingDefinitionError
+	if !errors.As(err, &missingErr) || missingErr.Name != "NUMBER" {
+		t.Errorf("Error: expected a MissingDefinitionError, got %v", err)
+	}
+
+	_, err = GenerateWithOptions(Options{LexerFile: writeSpec(t, "test.l", "%%\n[0-9]\n{\n}\n\n(a\n{\n}\n%%\n"), GrammarFile: grammarFile, PackageName: "test"})
+
+	var regexErr *SyntaxError
+	if !errors.As(err, &regexErr) || regexErr.Line != 6 || regexErr.Column != 1 {
+		t.Errorf("Error: expected a SyntaxError at line 6, got %v", err)
+	}
+}
@@ -8,13 +8,26 @@ import (
 	"strings"
 )
 
-func parseGrammar(filename string) (string, string, []rule) {
-	fmt.Println("Specified parser file:", filename)
+/*
+syncTerminal is a synchronizing terminal declared with %sync, where the parser resumes after a syntax error.
+*/
+type syncTerminal struct {
+	Name string
+	Pos  position
+}
+
+/*
+parseGrammar reads a grammar specification and returns its Go preamble, its axiom,
+its synchronizing terminals and its rules.
+The errors of the specification are *SyntaxError values or, if there are several, an ErrorList.
+*/
+func parseGrammar(filename string, d *diagnostics) (string, string, []syncTerminal, []rule, error) {
+	d.logger.Debug("reading grammar", "file", filename)
 
 	file, err := os.Open(filename)
 
 	if err != nil {
-		panic(err)
+		return "", "", nil, nil, err
 	}
 
 	defer file.Close()
@@ -23,137 +36,202 @@ func parseGrammar(filename string) (string, string, []rule) {
 	checkRegexpCompileError(err)
 	axiomRegex, err := regexp.Compile("^%axiom\\s*([a-zA-Z][a-zA-Z0-9]*)\\s*$")
 	checkRegexpCompileError(err)
+	syncRegex, err := regexp.Compile("^%sync((\\s+[a-zA-Z_][a-zA-Z0-9_]*)+)\\s*$")
+	checkRegexpCompileError(err)
 
 	scanner := bufio.NewScanner(file)
+	lineNum := 0
 
 	//Scan the preamble
 	goPreamble := make([]string, 0)
 
 	for scanner.Scan() {
 		curLine := scanner.Text()
+		lineNum++
 		if separatorRegex.MatchString(curLine) {
 			break
 		}
 		goPreamble = append(goPreamble, curLine)
 	}
 
-	//Scan the axiom
+	//Scan the axiom and the synchronizing terminals
 	axiom := ""
 	moreThanOneAxiomWarning := false
+	syncTerminals := make([]syncTerminal, 0)
 
 	for scanner.Scan() {
 		curLine := scanner.Text()
+		lineNum++
 		if separatorRegex.MatchString(curLine) {
 			break
 		}
-		axiomMatch := axiomRegex.FindStringSubmatch(curLine)
+		axiomMatch := axiomRegex.FindStringSubmatchIndex(curLine)
 		if axiomMatch != nil {
 			if axiom != "" && !moreThanOneAxiomWarning {
-				fmt.Println("Warning: axiom is defined more than once")
+				d.WarnAt(filename, position{lineNum, 1}, "the axiom is defined more than once")
 				moreThanOneAxiomWarning = true
 			}
-			axiom = axiomMatch[1]
+			axiom = curLine[axiomMatch[2]:axiomMatch[3]]
+		}
+		syncMatch := syncRegex.FindStringSubmatch(curLine)
+		if syncMatch != nil {
+			for _, name := range strings.Fields(syncMatch[1]) {
+				syncTerminals = append(syncTerminals, syncTerminal{name, position{lineNum, 1}})
+			}
 		}
 	}
 
 	ruleLines := make([]string, 0)
+	rulesFirstLine := lineNum + 1
 	for scanner.Scan() {
 		curLine := scanner.Text()
 		ruleLines = append(ruleLines, curLine)
 	}
 
-	rules := parseRules(strings.Join(ruleLines, "\n"))
+	if err := scanner.Err(); err != nil {
+		return "", "", nil, nil, err
+	}
+
+	rules, errs := parseRules(newSource(filename, ruleLines, rulesFirstLine))
+
+	if len(errs) > 0 {
+		return "", "", nil, nil, errs.Err()
+	}
 
-	return strings.Join(goPreamble, "\n"), axiom, rules
+	return strings.Join(goPreamble, "\n"), axiom, syncTerminals, rules, nil
 }
 
-func parseRules(input string) []rule {
-	bytes := []byte(input)
+/*
+parseRules reads the rules section of a grammar specification.
+After an error it skips to the end of the rules of the same lhs,
+so that several errors can be reported at once.
+*/
+func parseRules(src *source) ([]rule, ErrorList) {
+	bytes := src.bytes
 
 	rules := make([]rule, 0)
+	errs := make(ErrorList, 0)
 
 	pos := 0
 
 	pos = skipSpaces(bytes, pos)
 
 	for pos < len(bytes) {
-		firstRule := rule{}
+		var groupRules []rule
+		var err *SyntaxError
+		groupRules, pos, err = parseRuleGroup(src, pos)
 
-		var lhs string
-		lhs, pos = getIdentifier(bytes, pos)
-
-		if lhs == "" {
-			panic("Missing or invalid identifier for lhs")
+		if err != nil {
+			errs = append(errs, err)
+			pos = skipRuleGroup(bytes, pos)
+		} else {
+			rules = append(rules, groupRules...)
 		}
 
-		firstRule.LHS = lhs
-
 		pos = skipSpaces(bytes, pos)
+	}
 
-		if bytes[pos] == ':' {
-			pos++
-		} else {
-			panic("One of the rules is missing a colon between lhs and rhs")
-		}
+	return rules, errs
+}
+
+/*
+parseRuleGroup reads the rules of a lhs, separated by | and terminated by a semicolon,
+starting from pos. It returns the rules and the position following the semicolon,
+or the position of the first error.
+*/
+func parseRuleGroup(src *source, pos int) ([]rule, int, *SyntaxError) {
+	bytes := src.bytes
+
+	rules := make([]rule, 0)
+
+	lhsPos := pos
+
+	var lhs string
+	lhs, pos = getIdentifier(bytes, pos)
+
+	if lhs == "" {
+		return nil, pos, src.Errorf(pos, "expected an identifier for the lhs of a rule, found %s", describeByte(bytes, pos))
+	}
 
+	pos = skipSpaces(bytes, pos)
+
+	if pos < len(bytes) && bytes[pos] == ':' {
+		pos++
+	} else {
+		return nil, pos, src.Errorf(pos, "expected ':' after %s, found %s", lhs, describeByte(bytes, pos))
+	}
+
+	for {
 		pos = skipSpaces(bytes, pos)
 
-		firstRule.RHS = make([]string, 0)
+		curRule := rule{LHS: lhs, RHS: make([]string, 0), Pos: src.Position(lhsPos)}
 
-		for bytes[pos] != '{' {
+		for pos < len(bytes) && bytes[pos] != '{' {
 			var rhsToken string
 			rhsToken, pos = getIdentifier(bytes, pos)
 			if rhsToken == "" {
-				panic("Invalid identifier for rhs")
+				return nil, pos, src.Errorf(pos, "expected an identifier or a semantic action in the rhs of a rule for %s, found %s", lhs, describeByte(bytes, pos))
 			}
-			firstRule.RHS = append(firstRule.RHS, rhsToken)
+			curRule.RHS = append(curRule.RHS, rhsToken)
 			pos = skipSpaces(bytes, pos)
 		}
 
-		var semFun string
-		semFun, pos = getSemanticFunction(bytes, pos)
+		if pos >= len(bytes) {
+			return nil, pos, src.Errorf(pos, "a rule for %s is missing its semantic action", lhs)
+		}
 
-		firstRule.Action = semFun
+		actionPos := pos
 
-		rules = append(rules, firstRule)
+		var semFun string
+		var ok bool
+		semFun, pos, ok = getSemanticFunction(bytes, pos)
 
-		for {
-			pos = skipSpaces(bytes, pos)
-			if bytes[pos] == ';' {
-				pos++
-				break
-			} else if bytes[pos] == '|' {
-				pos++
-
-				pos = skipSpaces(bytes, pos)
-
-				nextRule := rule{}
-				nextRule.LHS = lhs
-				nextRule.RHS = make([]string, 0)
-
-				for bytes[pos] != '{' {
-					var rhsToken string
-					rhsToken, pos = getIdentifier(bytes, pos)
-					if rhsToken == "" {
-						panic("Invalid identifier for rhs")
-					}
-					nextRule.RHS = append(nextRule.RHS, rhsToken)
-					pos = skipSpaces(bytes, pos)
-				}
-
-				var semFun string
-				semFun, pos = getSemanticFunction(bytes, pos)
-
-				nextRule.Action = semFun
-
-				rules = append(rules, nextRule)
-			} else {
-				panic("Invalid character at the end of a rule")
-			}
+		if !ok {
+			return nil, pos, src.Errorf(actionPos, "the semantic action of a rule for %s is missing a closing brace", lhs)
 		}
 
+		curRule.Action = semFun
+
+		rules = append(rules, curRule)
+
 		pos = skipSpaces(bytes, pos)
+		if pos >= len(bytes) {
+			return nil, pos, src.Errorf(pos, "expected ';' at the end of the rules for %s", lhs)
+		} else if bytes[pos] == ';' {
+			pos++
+			return rules, pos, nil
+		} else if bytes[pos] == '|' {
+			pos++
+		} else {
+			return nil, pos, src.Errorf(pos, "expected ';' or '|' after a rule for %s, found %s", lhs, describeByte(bytes, pos))
+		}
 	}
+}
 
-	return rules
+/*
+skipRuleGroup skips the rest of a malformed group of rules, up to and including its semicolon.
+The semicolons inside the semantic actions are ignored.
+*/
+func skipRuleGroup(bytes []byte, pos int) int {
+	for pos < len(bytes) {
+		switch bytes[pos] {
+		case ';':
+			return pos + 1
+		case '{':
+			_, pos, _ = getSemanticFunction(bytes, pos)
+		default:
+			pos++
+		}
+	}
+	return pos
+}
+
+/*
+describeByte describes the character at pos for an error message.
+*/
+func describeByte(bytes []byte, pos int) string {
+	if pos >= len(bytes) {
+		return "the end of the file"
+	}
+	return fmt.Sprintf("%q", bytes[pos])
 }
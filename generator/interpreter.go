@@ -0,0 +1,406 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"go/scanner"
+	"go/token"
+	"io/ioutil"
+	"strings"
+
+	"github.com/simoneguidi94/gopapageno/papageno"
+)
+
+/*
+TokenAction computes the value of a terminal from the text matched by the lexer.
+If it returns an error the lexing fails.
+The text points to the input, so it must be copied if it is retained.
+*/
+type TokenAction func(text string) (interface{}, error)
+
+/*
+RuleAction is called for each reduction of a rule, after the syntactic tree has been built:
+lhs.Child is the first symbol of rhs and each symbol of rhs points to the following one through Next.
+*/
+type RuleAction func(lhs *papageno.Symbol, rhs []*papageno.Symbol)
+
+/*
+InterpreterActions contains the semantic actions of an interpreted parser,
+which replace the Go code of the specifications.
+Tokens is keyed by the name of a terminal, while Rules is keyed by a rule of the grammar
+written as in the specification, without the action: for example "E : E PLUS T".
+The actions are called concurrently by the parsing threads.
+*/
+type InterpreterActions struct {
+	Tokens map[string]TokenAction
+	Rules  map[string]RuleAction
+}
+
+/*
+lexAction is what the interpreter does when a lexer rule matches.
+begin is the start condition activated by the rule, or -1 if the rule does not change it.
+*/
+type lexAction struct {
+	result int
+	token  papageno.Token
+	action TokenAction
+	begin  int
+}
+
+/*
+Interpreter is a parser built at runtime from the specifications, without generating any code.
+The semantic actions of the specifications are ignored: by default the lexer sets the value
+of each terminal to the matched text and the parser only builds the syntactic tree,
+unless InterpreterActions supplies different actions.
+An Interpreter is not safe for concurrent use, since its parses share the memory pools of the parser
+and save their statistics in Stats: to parse with more goroutines at the same time, create an Interpreter for each of them.
+The memory pools are kept between the parses, so the tree returned by a parse is valid until the next parse, Reset or Release.
+*/
+type Interpreter struct {
+	lexer    *papageno.Lexer
+	grammar  papageno.Grammar
+	parser   papageno.Parser
+	tokens   map[string]papageno.Token
+	names    map[papageno.Token]string
+	lexRules []lexAction
+	rules    []rule
+	actions  []RuleAction
+
+	//Warnings contains the warnings about the specifications
+	Warnings []Warning
+	//Stats contains some statistics that may be checked after a parse, until the next one
+	Stats papageno.ParsingStats
+}
+
+/*
+NewInterpreter reads the lexer and the grammar specifications, performing the same analysis
+done by Generate, and returns an Interpreter that parses with the resulting tables.
+If lexerFilename is empty the interpreter can only parse symbols that were already lexed.
+The terminal produced by each lexer rule is inferred from its action, which must name exactly
+one terminal; actions naming none are treated as skipping the text if they contain _SKIP,
+or as lexing errors otherwise. Likewise an action may activate a start condition with BEGIN,
+but only one.
+*/
+func NewInterpreter(lexerFilename string, parserFilename string, actions InterpreterActions) (*Interpreter, error) {
+	a, err := analyze(lexerFilename, parserFilename, newDiagnostics(nil))
+
+	if err != nil {
+		return nil, err
+	}
+
+	in := &Interpreter{
+		tokens: make(map[string]papageno.Token),
+		names:  make(map[papageno.Token]string),
+		rules:  a.sortedRules,
+
+		Warnings: a.warnings,
+	}
+
+	for _, t := range append(a.nonterminals, a.terminals...) {
+		token := papageno.Token(tokenToInt(t, a.nonterminals, a.terminals))
+		in.tokens[t] = token
+		in.names[token] = t
+	}
+	in.names[papageno.ErrorToken] = "_ERROR"
+
+	for name := range actions.Tokens {
+		if !a.terminals.Contains(name) || name == "_TERM" {
+			return nil, &ActionError{name, "the grammar has no such terminal"}
+		}
+	}
+
+	if lexerFilename != "" {
+		err = in.initLexer(a, actions.Tokens)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = in.initActions(a, actions.Rules)
+
+	if err != nil {
+		return nil, err
+	}
+
+	compressedTrie := createTrie(a.sortedRules, a.nonterminals, a.terminals)
+
+	syncTerminals := make([]papageno.Token, len(a.syncTerminals))
+	for i, t := range a.syncTerminals {
+		syncTerminals[i] = in.tokens[t]
+	}
+
+	maxRHSLen := 0
+	for _, r := range a.sortedRules {
+		if len(r.RHS) > maxRHSLen {
+			maxRHSLen = len(r.RHS)
+		}
+	}
+
+	in.grammar = papageno.Grammar{
+		NumTerminals:   len(a.terminals),
+		MaxRHSLen:      maxRHSLen,
+		Empty:          in.tokens["_EMPTY"],
+		Term:           in.tokens["_TERM"],
+		CompressedTrie: compressedTrie.Compress(a.nonterminals, a.terminals),
+		PrecMatrix:     packPrecMatrix(a.terminals, a.precMatrix),
+		Function:       in.function,
+		TokenString:    in.TokenString,
+		SyncTerminals:  syncTerminals,
+	}
+
+	in.parser = papageno.Parser{Lexer: in.lexer, Grammar: &in.grammar}
+
+	return in, nil
+}
+
+/*
+initLexer builds the automata of the lexer and infers the terminal produced by each lexer rule.
+*/
+func (in *Interpreter) initLexer(a *analysis, tokenActions map[string]TokenAction) error {
+	in.lexRules = make([]lexAction, len(a.lexRules))
+
+	for i, r := range a.lexRules {
+		terminals, names := actionIdentifiers(r.Action, a.terminals)
+
+		switch {
+		case len(terminals) == 1:
+			in.lexRules[i] = lexAction{papageno.LexCorrect, in.tokens[terminals[0]], tokenActions[terminals[0]], -1}
+		case len(terminals) > 1:
+			return &TokenInferenceError{i, r.Regex, terminals}
+		case names["_SKIP"]:
+			in.lexRules[i] = lexAction{result: papageno.LexSkip, begin: -1}
+		case names["_ERROR"]:
+			in.lexRules[i] = lexAction{result: papageno.LexError, begin: -1}
+		default:
+			return &TokenInferenceError{i, r.Regex, nil}
+		}
+
+		targets := beginTargets(r.Action)
+		if len(targets) > 1 {
+			return &StartConditionError{strings.Join(targets, ", "), fmt.Sprintf("cannot be activated by the same lexer rule %d in the interpreter", i)}
+		} else if len(targets) == 1 {
+			in.lexRules[i].begin = conditionIndex(a.conditions, targets[0])
+		}
+	}
+
+	automata := make([]papageno.LexerDfa, len(a.dfas))
+	for c, dfa := range a.dfas {
+		automata[c] = lexerDfa(dfa)
+	}
+
+	in.lexer = &papageno.Lexer{
+		Automata:           automata,
+		CutPointsAutomaton: lexerDfa(a.cutPointsDfa),
+		Function:           in.lexerFunction,
+	}
+
+	return nil
+}
+
+/*
+initActions associates the actions to the rules used by the parser, which are obtained
+by rewriting the rules of the specification.
+*/
+func (in *Interpreter) initActions(a *analysis, ruleActions map[string]RuleAction) error {
+	origins := make(map[string]int)
+	for i, r := range a.rules {
+		origins[ruleKey(r.LHS, r.RHS)] = i
+	}
+
+	reduced := make(map[int]bool)
+	for _, r := range a.sortedRules {
+		reduced[r.Origin] = true
+	}
+
+	byOrigin := make(map[int]RuleAction)
+	for key, action := range ruleActions {
+		origin, ok := origins[normalizeRuleKey(key)]
+		if !ok {
+			return &ActionError{key, "the grammar has no such rule"}
+		}
+		if !reduced[origin] {
+			return &ActionError{key, "the rule is never reduced, since it is a copy rule or another rule has the same rhs"}
+		}
+		byOrigin[origin] = action
+	}
+
+	in.actions = make([]RuleAction, len(a.sortedRules))
+	for i, r := range a.sortedRules {
+		in.actions[i] = byOrigin[r.Origin]
+	}
+
+	return nil
+}
+
+/*
+lexerFunction is the semantic function of the lexer used by the interpreter.
+*/
+func (in *Interpreter) lexerFunction(thread int, ruleNum int, yytext string, genSym *papageno.Symbol, condition *int) int {
+	r := in.lexRules[ruleNum]
+
+	if r.begin >= 0 {
+		*condition = r.begin
+	}
+
+	if r.result != papageno.LexCorrect {
+		return r.result
+	}
+
+	//yytext points to the input, so it is copied before being stored
+	var value interface{} = string([]byte(yytext))
+	if r.action != nil {
+		var err error
+		value, err = r.action(yytext)
+		if err != nil {
+			return papageno.LexError
+		}
+	}
+
+	*genSym = papageno.Symbol{Token: r.token, Value: value}
+
+	return papageno.LexCorrect
+}
+
+/*
+function is the semantic function of the parser used by the interpreter.
+*/
+func (in *Interpreter) function(thread int, ruleNum uint16, lhs *papageno.Symbol, rhs []*papageno.Symbol) {
+	if len(rhs) > 0 {
+		lhs.Child = rhs[0]
+		for i := 0; i < len(rhs)-1; i++ {
+			rhs[i].Next = rhs[i+1]
+		}
+	}
+
+	if action := in.actions[ruleNum]; action != nil {
+		action(lhs, rhs)
+	} else if in.rules[ruleNum].Origin == -1 {
+		//The rules of the new axiom are added by the generator and propagate the value of the old one
+		lhs.Value = rhs[0].Value
+	}
+}
+
+/*
+ParseString parses a string in parallel, using the given number of threads.
+It returns the symbol at the root of the syntactic tree, or an error if the string could not be parsed.
+*/
+func (in *Interpreter) ParseString(str []byte, numThreads int) (*papageno.Symbol, error) {
+	if in.lexer == nil {
+		return nil, errors.New("the interpreter has no lexer, use ParseTokens")
+	}
+
+	root, err := in.parser.ParseString(str, numThreads)
+	in.Stats = in.parser.Stats
+
+	return root, err
+}
+
+/*
+ParseFile parses a file in parallel, using the given number of threads.
+It returns the symbol at the root of the syntactic tree, or an error if the file could not be parsed.
+*/
+func (in *Interpreter) ParseFile(filename string, numThreads int) (*papageno.Symbol, error) {
+	bytes, err := ioutil.ReadFile(filename)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return in.ParseString(bytes, numThreads)
+}
+
+/*
+ParseTokens parses in parallel a list of symbols that were already lexed.
+The tokens of the symbols can be obtained with Token.
+*/
+func (in *Interpreter) ParseTokens(tokens []papageno.Symbol, numThreads int) (*papageno.Symbol, error) {
+	root, err := in.parser.ParseTokens(tokens, numThreads)
+	in.Stats = in.parser.Stats
+
+	return root, err
+}
+
+/*
+Reset clears the memory pools of the parser, so that they do not reference the tree of the last parse anymore,
+while keeping them for the next parse.
+*/
+func (in *Interpreter) Reset() {
+	in.parser.Reset()
+}
+
+/*
+Release drops the memory pools of the parser, so that they can be collected by the garbage collector.
+The next parse allocates them again.
+*/
+func (in *Interpreter) Release() {
+	in.parser.Release()
+}
+
+/*
+Token returns the token of a terminal or a nonterminal of the grammar.
+The nonterminals are the ones obtained after the elimination of repeated right hand sides,
+such as E_S, as printed by Explain.
+*/
+func (in *Interpreter) Token(name string) (papageno.Token, bool) {
+	token, ok := in.tokens[name]
+	return token, ok
+}
+
+/*
+TokenString returns the name of a token.
+*/
+func (in *Interpreter) TokenString(token papageno.Token) string {
+	return in.names[token]
+}
+
+/*
+actionIdentifiers scans the Go code of an action and returns, in order of appearance and without repetitions,
+the terminals it names, together with the set of every identifier it contains.
+*/
+func actionIdentifiers(action string, terminals stringSet) ([]string, map[string]bool) {
+	src := []byte(action)
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(src))
+
+	var s scanner.Scanner
+	s.Init(file, src, nil, 0)
+
+	found := make([]string, 0)
+	names := make(map[string]bool)
+	prevTok := token.ILLEGAL
+	for {
+		_, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		if tok == token.IDENT && prevTok != token.PERIOD && !names[lit] {
+			names[lit] = true
+			if terminals.Contains(lit) && lit != "_TERM" {
+				found = append(found, lit)
+			}
+		}
+		prevTok = tok
+	}
+
+	return found, names
+}
+
+/*
+ruleKey returns the key of a rule in InterpreterActions.Rules.
+*/
+func ruleKey(lhs string, rhs []string) string {
+	return lhs + " : " + strings.Join(rhs, " ")
+}
+
+/*
+normalizeRuleKey rewrites a key of InterpreterActions.Rules as returned by ruleKey,
+so that the spacing of the key does not matter.
+*/
+func normalizeRuleKey(key string) string {
+	colon := strings.Index(key, ":")
+	if colon < 0 {
+		return key
+	}
+	return ruleKey(strings.TrimSpace(key[:colon]), strings.Fields(key[colon+1:]))
+}
@@ -0,0 +1,76 @@
+package generator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/simoneguidi94/gopapageno/generator/regex"
+	"github.com/simoneguidi94/gopapageno/papageno"
+)
+
+/*
+maxAutomatonStates is the largest number of states of a lexer automaton,
+since its transitions are stored as uint16 and one value marks the missing ones.
+*/
+const maxAutomatonStates = papageno.LexerNoTransition
+
+/*
+lexerDfa converts an automaton built by the regex package to the representation used by the runtime,
+grouping the bytes in equivalence classes so that the table has a column for each class instead of each byte.
+The automaton must have at most maxAutomatonStates states.
+*/
+func lexerDfa(dfa regex.Dfa) papageno.LexerDfa {
+	states := dfa.GetStates()
+
+	var automaton papageno.LexerDfa
+
+	//Two bytes are in the same class if every state has the same transition with both
+	representatives := make([]int, 0)
+	classOfColumn := make(map[string]int)
+	for c := 0; c < 256; c++ {
+		key := columnKey(states, c)
+		class, ok := classOfColumn[key]
+		if !ok {
+			class = len(representatives)
+			classOfColumn[key] = class
+			representatives = append(representatives, c)
+		}
+		automaton.Classes[c] = uint8(class)
+	}
+
+	automaton.NumClasses = len(representatives)
+	automaton.Transitions = make([]uint16, len(states)*automaton.NumClasses)
+	automaton.IsFinal = make([]bool, len(states))
+	automaton.AssociatedRules = make([][]int, len(states))
+
+	for i, state := range states {
+		for class, c := range representatives {
+			next := uint16(papageno.LexerNoTransition)
+			if nextState := state.Transitions[c]; nextState != nil {
+				next = uint16(nextState.Num)
+			}
+			automaton.Transitions[i*automaton.NumClasses+class] = next
+		}
+		automaton.IsFinal[i] = state.IsFinal
+		automaton.AssociatedRules[i] = append([]int{}, state.AssociatedRules...)
+		sort.Ints(automaton.AssociatedRules[i])
+	}
+
+	return automaton
+}
+
+/*
+columnKey returns a string that identifies the transitions of every state with the byte c.
+*/
+func columnKey(states []*regex.DfaState, c int) string {
+	var sb strings.Builder
+	for _, state := range states {
+		if nextState := state.Transitions[c]; nextState == nil {
+			sb.WriteString("-,")
+		} else {
+			fmt.Fprintf(&sb, "%d,", nextState.Num)
+		}
+	}
+	return sb.String()
+}
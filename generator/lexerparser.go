@@ -5,16 +5,25 @@ import (
 	"fmt"
 	"os"
 	"regexp"
+	"sort"
 	"strings"
+
+	"github.com/simoneguidi94/gopapageno/generator/regex"
 )
 
-func parseLexer(filename string) ([]lexRule, string, string) {
-	fmt.Println("Specified lexer file:", filename)
+/*
+parseLexer reads a lexer specification and returns its rules, its start conditions
+(the first of which is INITIAL), the regular expression of the cut points, the Go code
+and the options of its regular expressions.
+The errors of the specification are *SyntaxError values or, if there are several, an ErrorList.
+*/
+func parseLexer(filename string, d *diagnostics) ([]lexRule, []lexCondition, *cutPointsSpec, string, regex.Options, error) {
+	d.logger.Debug("reading lexer", "file", filename)
 
 	file, err := os.Open(filename)
 
 	if err != nil {
-		panic(err)
+		return nil, nil, nil, "", regex.Options{}, err
 	}
 
 	defer file.Close()
@@ -25,42 +34,98 @@ func parseLexer(filename string) ([]lexRule, string, string) {
 	checkRegexpCompileError(err)
 	definitionRegex, err := regexp.Compile("^([a-zA-Z][a-zA-Z0-9]*)\\s*(.+)$")
 	checkRegexpCompileError(err)
+	conditionsRegex, err := regexp.Compile("^%([sx])\\s+(.+)$")
+	checkRegexpCompileError(err)
+	optionsRegex, err := regexp.Compile("^%option\\s+(.+)$")
+	checkRegexpCompileError(err)
 
 	scanner := bufio.NewScanner(file)
+	lineNum := 0
+
+	errs := make(ErrorList, 0)
 
 	//Scan the definitions section
-	cutPoints := ""
+	cutPoints := &cutPointsSpec{}
 	definitions := make(map[string]string)
+	definitionPositions := make(map[string]position)
+	conditions := []lexCondition{{initialCondition, false, position{}}}
+	var opts regex.Options
 
 	for scanner.Scan() {
 		curLine := scanner.Text()
+		lineNum++
 		if separatorRegex.MatchString(curLine) {
 			break
 		}
-		defMatch := definitionRegex.FindStringSubmatch(curLine)
-		cutPointsMatch := cutPointsRegex.FindStringSubmatch(curLine)
+		defMatch := definitionRegex.FindStringSubmatchIndex(curLine)
+		cutPointsMatch := cutPointsRegex.FindStringSubmatchIndex(curLine)
+		conditionsMatch := conditionsRegex.FindStringSubmatch(curLine)
+		optionsMatch := optionsRegex.FindStringSubmatch(curLine)
 		if defMatch != nil {
-			definitions[defMatch[1]] = strings.TrimSpace(defMatch[2])
+			name := curLine[defMatch[2]:defMatch[3]]
+			definitions[name] = strings.TrimSpace(curLine[defMatch[4]:defMatch[5]])
+			definitionPositions[name] = position{lineNum, defMatch[4] + 1}
 		} else if cutPointsMatch != nil {
-			cutPoints = cutPointsMatch[1]
+			cutPoints = &cutPointsSpec{curLine[cutPointsMatch[2]:cutPointsMatch[3]], position{lineNum, cutPointsMatch[2] + 1}}
+		} else if conditionsMatch != nil {
+			for _, name := range strings.Fields(conditionsMatch[2]) {
+				pos := position{lineNum, strings.Index(curLine, name) + 1}
+				if conditionIndex(conditions, name) >= 0 {
+					errs = append(errs, newSyntaxError(filename, pos, &StartConditionError{name, "is declared more than once"}))
+					continue
+				}
+				conditions = append(conditions, lexCondition{name, conditionsMatch[1] == "x", pos})
+			}
+		} else if optionsMatch != nil {
+			for _, name := range strings.Fields(optionsMatch[1]) {
+				switch name {
+				case "utf8":
+					opts.UTF8 = true
+				default:
+					errs = append(errs, newSyntaxError(filename, position{lineNum, strings.Index(curLine, name) + 1}, fmt.Errorf("unknown option %q", name)))
+				}
+			}
 		}
 	}
 
-	fmt.Println("Definitions:")
-	for k, v := range definitions {
-		fmt.Printf("%s: %s\n", k, v)
+	definitions, err = expandDefinitions(definitions)
+
+	if err != nil {
+		//Locate the error at the definition whose expansion failed
+		var pos position
+		if missingErr, ok := err.(*MissingDefinitionError); ok {
+			pos = definitionPositions[missingErr.Definition]
+		} else if cycleErr, ok := err.(*DefinitionCycleError); ok {
+			pos = definitionPositions[cycleErr.Cycle[0]]
+		}
+		errs = append(errs, newSyntaxError(filename, pos, err))
+		return nil, nil, nil, "", regex.Options{}, errs.Err()
 	}
 
+	definitionNames := make([]string, 0, len(definitions))
+	for k := range definitions {
+		definitionNames = append(definitionNames, k)
+	}
+	sort.Strings(definitionNames)
+	definitionLines := make(lines, len(definitionNames))
+	for i, k := range definitionNames {
+		definitionLines[i] = fmt.Sprintf("%s: %s", k, definitions[k])
+	}
+	d.Dump("definitions", definitionLines)
+
 	ruleLines := make([]string, 0)
+	rulesFirstLine := lineNum + 1
 	for scanner.Scan() {
 		curLine := scanner.Text()
+		lineNum++
 		if separatorRegex.MatchString(curLine) {
 			break
 		}
 		ruleLines = append(ruleLines, curLine)
 	}
 
-	lexRules := parseLexRules(strings.Join(ruleLines, "\n"), definitions)
+	lexRules, ruleErrs := parseLexRules(newSource(filename, ruleLines, rulesFirstLine), definitions, conditions)
+	errs = append(errs, ruleErrs...)
 
 	codeLines := make([]string, 0)
 	for scanner.Scan() {
@@ -68,29 +133,187 @@ func parseLexer(filename string) ([]lexRule, string, string) {
 		codeLines = append(codeLines, curLine)
 	}
 
-	return lexRules, cutPoints, strings.Join(codeLines, "\n")
+	if err := scanner.Err(); err != nil {
+		return nil, nil, nil, "", regex.Options{}, err
+	}
+
+	if len(errs) > 0 {
+		return nil, nil, nil, "", regex.Options{}, errs.Err()
+	}
+
+	return lexRules, conditions, cutPoints, strings.Join(codeLines, "\n"), opts, nil
+}
+
+/*
+cutPointsSpec is the regular expression of the cut points and its position in the lexer specification.
+Regex is empty if the specification does not define the cut points.
+*/
+type cutPointsSpec struct {
+	Regex string
+	Pos   position
+}
+
+/*
+expandDefinitions replaces the references to other definitions, such as {IDENT},
+in the values of the definitions, transitively.
+It returns a *DefinitionCycleError if some definitions refer to each other.
+*/
+func expandDefinitions(definitions map[string]string) (map[string]string, error) {
+	names := make([]string, 0, len(definitions))
+	for name := range definitions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	expanded := make(map[string]string)
+
+	for _, name := range names {
+		_, err := expandDefinition(name, definitions, expanded, nil)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return expanded, nil
+}
+
+/*
+expandDefinition expands the definition with the given name, saving it in expanded.
+path contains the definitions whose expansion is in progress, to detect the cycles.
+*/
+func expandDefinition(name string, definitions map[string]string, expanded map[string]string, path []string) (string, error) {
+	if value, ok := expanded[name]; ok {
+		return value, nil
+	}
+
+	for i, n := range path {
+		if n == name {
+			cycle := make([]string, 0, len(path)-i+1)
+			cycle = append(cycle, path[i:]...)
+			return "", &DefinitionCycleError{append(cycle, name)}
+		}
+	}
+
+	path = append(path, name)
+
+	bytes := []byte(definitions[name])
+
+	var sb strings.Builder
+
+	pos := 0
+	for pos < len(bytes) {
+		if bytes[pos] == '\\' {
+			end := escapeEnd(bytes, pos)
+			sb.Write(bytes[pos:end])
+			pos = end
+			continue
+		}
+		if bytes[pos] == '{' {
+			identifier, end := getIdentifier(bytes, pos+1)
+			if identifier != "" && end < len(bytes) && bytes[end] == '}' {
+				if _, ok := definitions[identifier]; !ok {
+					return "", &MissingDefinitionError{identifier, name}
+				}
+
+				value, err := expandDefinition(identifier, definitions, expanded, path)
+
+				if err != nil {
+					return "", err
+				}
+
+				//The expansion is grouped, so that it is not split by the operators around it, as in flex
+				sb.WriteString("(" + value + ")")
+				pos = end + 1
+				continue
+			}
+		}
+		sb.WriteByte(bytes[pos])
+		pos++
+	}
+
+	expanded[name] = sb.String()
+
+	return expanded[name], nil
 }
 
-func parseLexRules(input string, definitions map[string]string) []lexRule {
-	bytes := []byte(input)
+/*
+quantifierRegex matches a counted quantifier of a regular expression, such as {3} or {2,5}.
+Since the names of the definitions cannot start with a digit, they are never confused with the references.
+*/
+var quantifierRegex = regexp.MustCompile(`^\{[0-9]+(,[0-9]*)?\}`)
+
+/*
+escapeEnd returns the position following the escape of a regular expression that starts with a backslash at pos.
+The braces of the escapes \p{...}, \P{...} and \u{...} belong to the escape,
+so they are not confused with the references to the definitions.
+*/
+func escapeEnd(bytes []byte, pos int) int {
+	if pos+2 < len(bytes) && (bytes[pos+1] == 'p' || bytes[pos+1] == 'P' || bytes[pos+1] == 'u') && bytes[pos+2] == '{' {
+		end := pos + 3
+		for end < len(bytes) && bytes[end] != '}' {
+			end++
+		}
+		if end < len(bytes) {
+			return end + 1
+		}
+	}
+	if pos+2 > len(bytes) {
+		return len(bytes)
+	}
+	return pos + 2
+}
+
+/*
+parseLexRules reads the rules section of a lexer specification, replacing the references to the definitions.
+After an error it goes on with the next rule, so that several errors can be reported at once.
+*/
+func parseLexRules(src *source, definitions map[string]string, conditions []lexCondition) ([]lexRule, ErrorList) {
+	bytes := src.bytes
 
 	lexRules := make([]lexRule, 0)
+	errs := make(ErrorList, 0)
 
 	pos := 0
 
 	pos = skipSpaces(bytes, pos)
 
 	curRegex := ""
+	var curConditions []string
+	ruleStart := true
+	rulePos := 0
+	ruleValid := true
 
 	for pos < len(bytes) {
+		//A rule may start with the list of its start conditions
+		if ruleStart {
+			var prefixLen int
+			rulePos = pos
+			curConditions, prefixLen = parseConditionPrefix(bytes[pos:], conditions)
+			pos += prefixLen
+			ruleStart = false
+			ruleValid = true
+		}
+
 		startingPos := pos
 
-		//Read anything until a { is reached
-		for pos < len(bytes) && bytes[pos] != '{' {
-			pos++
+		//Read anything until a { is reached, skipping the escaped chars and the counted quantifiers
+		for pos < len(bytes) {
+			if bytes[pos] == '\\' {
+				pos = escapeEnd(bytes, pos)
+			} else if bytes[pos] == '{' {
+				quantifier := quantifierRegex.Find(bytes[pos:])
+				if quantifier == nil {
+					break
+				}
+				pos += len(quantifier)
+			} else {
+				pos++
+			}
 		}
 
 		if pos >= len(bytes) {
+			errs = append(errs, src.Errorf(rulePos, "the lexer rule %s is missing its semantic action", strings.TrimSpace(curRegex+string(bytes[startingPos:]))))
 			break
 		}
 
@@ -103,38 +326,43 @@ func parseLexRules(input string, definitions map[string]string) []lexRule {
 		var identifier string
 		identifier, pos = getIdentifier(bytes, pos)
 		curlyRParPos := pos
-		if bytes[curlyRParPos] == '}' && identifier != "" {
-			foundInDefinitions := false
-			for key, value := range definitions {
-				if key == identifier {
-					foundInDefinitions = true
-					curRegex += string(bytes[startingPos:curlyLParPos]) + value
-					pos++
-					break
-				}
-			}
+		if curlyRParPos < len(bytes) && bytes[curlyRParPos] == '}' && identifier != "" {
+			value, foundInDefinitions := definitions[identifier]
 
 			if !foundInDefinitions {
-				fmt.Println(lexRules)
-				panic(fmt.Sprintf("Missing definition \"%s\"", identifier))
+				errs = append(errs, src.ErrorAt(curlyLParPos, &MissingDefinitionError{identifier, ""}))
+				ruleValid = false
 			}
+
+			//The expansion is grouped, so that a quantifier that follows it applies to all of it, as in flex
+			curRegex += string(bytes[startingPos:curlyLParPos]) + "(" + value + ")"
+			pos++
 		} else {
 			pos = curlyLParPos
 
-			var semFun string
-			semFun, pos = getSemanticFunction(bytes, pos)
+			semFun, newPos, ok := getSemanticFunction(bytes, pos)
+
+			if !ok {
+				errs = append(errs, src.Errorf(curlyLParPos, "the semantic action is missing a closing brace"))
+				break
+			}
+
+			pos = newPos
 
 			curRegex += string(bytes[startingPos:curlyLParPos])
 
 			curRegex = strings.Trim(curRegex, " \t\r\n")
 
-			lexRules = append(lexRules, lexRule{curRegex, semFun})
+			if ruleValid {
+				lexRules = append(lexRules, lexRule{curRegex, semFun, curConditions, src.Position(rulePos)})
+			}
 
 			curRegex = ""
+			ruleStart = true
 
 			pos = skipSpaces(bytes, pos)
 		}
 	}
 
-	return lexRules
+	return lexRules, errs
 }
@@ -0,0 +1,118 @@
+package generator
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/simoneguidi94/gopapageno/generator/regex"
+)
+
+/*
+ruleOverlap is a pair of lexer rules that match the same strings, such as Example.
+Those strings are lexed with Chosen, the rule that comes first in the specification.
+*/
+type ruleOverlap struct {
+	Chosen  int
+	Other   int
+	Example string
+}
+
+/*
+checkLexRules analyzes the final states of the automata of the lexer, whose strings are lexed with
+the first of their rules. It warns about the rules that are never the first one, since they can never match,
+and returns the pairs of rules that match the same strings, sorted by rule.
+*/
+func checkLexRules(filename string, lexRules []lexRule, dfas []regex.Dfa, d *diagnostics) []ruleOverlap {
+	chosen := make([]bool, len(lexRules))
+	overlaps := make(map[[2]int]string)
+
+	for _, dfa := range dfas {
+		states := dfa.GetStates()
+		examples := shortestExamples(dfa)
+
+		for _, state := range states {
+			example, reached := examples[state.Num]
+			if !reached || !state.IsFinal || len(state.AssociatedRules) == 0 {
+				continue
+			}
+
+			rules := append([]int{}, state.AssociatedRules...)
+			sort.Ints(rules)
+
+			chosen[rules[0]] = true
+			for _, other := range rules[1:] {
+				if other == rules[0] {
+					continue
+				}
+				key := [2]int{rules[0], other}
+				if oldExample, ok := overlaps[key]; !ok || len(example) < len(oldExample) {
+					overlaps[key] = example
+				}
+			}
+		}
+	}
+
+	result := make([]ruleOverlap, 0, len(overlaps))
+	for key, example := range overlaps {
+		result = append(result, ruleOverlap{key[0], key[1], example})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Chosen != result[j].Chosen {
+			return result[i].Chosen < result[j].Chosen
+		}
+		return result[i].Other < result[j].Other
+	})
+
+	for i, r := range lexRules {
+		if chosen[i] {
+			continue
+		}
+
+		//Report the earliest rule that shadows it, with its shortest example
+		var shadowing *ruleOverlap
+		for j := range result {
+			if result[j].Other == i && (shadowing == nil || len(result[j].Example) < len(shadowing.Example)) {
+				shadowing = &result[j]
+			}
+		}
+
+		if shadowing == nil {
+			d.WarnAt(filename, r.Pos, "the lexer rule can never match, since it does not match any non-empty string")
+		} else {
+			d.WarnAt(filename, r.Pos, fmt.Sprintf("the lexer rule can never match, since the strings it matches, such as %q, are matched by the earlier rule at line %d", shadowing.Example, lexRules[shadowing.Chosen].Pos.Line))
+		}
+	}
+
+	return result
+}
+
+/*
+shortestExamples returns, for each state of the automaton reachable with a non-empty string,
+the shortest such string, choosing the smallest bytes among the strings of the same length.
+Byte 0 is skipped, since it is the empty transition of the automata and no lexer rule can match it.
+*/
+func shortestExamples(dfa regex.Dfa) map[int]string {
+	examples := make(map[int]string)
+	toCheck := make([]*regex.DfaState, 0)
+
+	visit := func(state *regex.DfaState, prefix string) {
+		for c := 1; c < 256; c++ {
+			nextState := state.Transitions[c]
+			if nextState == nil {
+				continue
+			}
+			if _, ok := examples[nextState.Num]; !ok {
+				examples[nextState.Num] = prefix + string([]byte{byte(c)})
+				toCheck = append(toCheck, nextState)
+			}
+		}
+	}
+
+	visit(dfa.Initial, "")
+	for nextStateToCheckPos := 0; nextStateToCheckPos < len(toCheck); nextStateToCheckPos++ {
+		state := toCheck[nextStateToCheckPos]
+		visit(state, examples[state.Num])
+	}
+
+	return examples
+}
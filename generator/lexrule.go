@@ -2,13 +2,24 @@ package generator
 
 import (
 	"fmt"
+	"strings"
 )
 
+/*
+lexRule is a rule of the lexer. Conditions contains the start conditions listed
+in the prefix of the rule, or is nil if the rule has no prefix.
+Pos is the position of the rule in the lexer specification.
+*/
 type lexRule struct {
-	Regex  string
-	Action string
+	Regex      string
+	Action     string
+	Conditions []string
+	Pos        position
 }
 
 func (r lexRule) String() string {
+	if r.Conditions != nil {
+		return fmt.Sprintf("<%s>%s: %s", strings.Join(r.Conditions, ","), r.Regex, r.Action)
+	}
 	return fmt.Sprintf("%s: %s", r.Regex, r.Action)
 }
@@ -1,8 +1,9 @@
 package generator
 
 import (
-	"errors"
 	"fmt"
+	"io"
+	"sort"
 )
 
 type precMatrix map[string]map[string]uint16
@@ -21,11 +22,17 @@ func newPrecMatrix(terminals stringSet) precMatrix {
 }
 
 func (matrix precMatrix) String() string {
+	keys := make([]string, 0, len(matrix))
+	for key := range matrix {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
 	s := ""
-	for key, row := range matrix {
+	for _, key := range keys {
 		s += key + ": [ "
-		for key2, prec := range row {
-			s += key2 + ":" + precToString(prec) + " "
+		for _, key2 := range keys {
+			s += key2 + ":" + precToString(matrix[key][key2]) + " "
 		}
 		s += "]\n"
 	}
@@ -33,12 +40,11 @@ func (matrix precMatrix) String() string {
 	return string(bytes[:len(bytes)-1])
 }
 
-func createPrecMatrix(rules []rule, nonterminals stringSet, terminals stringSet) (precMatrix, error) {
+func createPrecMatrix(rules []rule, nonterminals stringSet, terminals stringSet, d *diagnostics) (precMatrix, error) {
 	precMatrix := newPrecMatrix(terminals)
 	lts, rts := getTerminalSets(rules, nonterminals, terminals)
 
-	fmt.Println("lts:", lts)
-	fmt.Println("rts:", rts)
+	d.logger.Debug("terminal sets", "lts", fmt.Sprint(lts), "rts", fmt.Sprint(rts))
 
 	for _, rule := range rules {
 		rhs := rule.RHS
@@ -50,14 +56,14 @@ func createPrecMatrix(rules []rule, nonterminals stringSet, terminals stringSet)
 			if terminals.Contains(token1) && terminals.Contains(token2) {
 				//Check if the matrix already contains an entry for this couple
 				if precMatrix[token1][token2] != _NO && precMatrix[token1][token2] != _EQ {
-					return precMatrix, errors.New(fmt.Sprintf("Error: the precedence relation is not unique between %s and %s", token1, token2))
+					return precMatrix, &PrecedenceConflictError{token1, token2, precMatrix[token1][token2], _EQ}
 				}
 				precMatrix[token1][token2] = _EQ
 			} else if nonterminals.Contains(token1) && terminals.Contains(token2) {
 				for _, token := range *rts[token1] {
 					//Check if the matrix already contains an entry for this couple
 					if precMatrix[token][token2] != _NO && precMatrix[token][token2] != _TA {
-						return precMatrix, errors.New(fmt.Sprintf("Error: the precedence relation is not unique between %s and %s", token, token2))
+						return precMatrix, &PrecedenceConflictError{token, token2, precMatrix[token][token2], _TA}
 					}
 					precMatrix[token][token2] = _TA
 				}
@@ -65,12 +71,12 @@ func createPrecMatrix(rules []rule, nonterminals stringSet, terminals stringSet)
 				for _, token := range *lts[token2] {
 					//Check if the matrix already contains an entry for this couple
 					if precMatrix[token1][token] != _NO && precMatrix[token1][token] != _YI {
-						return precMatrix, errors.New(fmt.Sprintf("Error: the precedence relation is not unique between %s and %s", token1, token))
+						return precMatrix, &PrecedenceConflictError{token1, token, precMatrix[token1][token], _YI}
 					}
 					precMatrix[token1][token] = _YI
 				}
 			} else {
-				return precMatrix, errors.New(fmt.Sprintf("Error: the rule %s is not in operator precedence form", rule.String()))
+				return precMatrix, &OperatorFormError{rule.String()}
 			}
 		}
 
@@ -83,7 +89,7 @@ func createPrecMatrix(rules []rule, nonterminals stringSet, terminals stringSet)
 			if terminals.Contains(token1) && nonterminals.Contains(token2) && terminals.Contains(token3) {
 				//Check if the matrix already contains an entry for this couple
 				if precMatrix[token1][token3] != _NO && precMatrix[token1][token3] != _EQ {
-					return precMatrix, errors.New(fmt.Sprintf("Error: the precedence relation is not unique between %s and %s", token1, token3))
+					return precMatrix, &PrecedenceConflictError{token1, token3, precMatrix[token1][token3], _EQ}
 				}
 				precMatrix[token1][token3] = _EQ
 			}
@@ -163,3 +169,29 @@ func getTerminalSets(rules []rule, nonterminals stringSet, terminals stringSet)
 
 	return lts, rts
 }
+
+/*
+writePrecMatrix writes the precedence matrix as a table, with rows and columns sorted as the terminals.
+*/
+func writePrecMatrix(w io.Writer, terminals stringSet, matrix precMatrix) {
+	width := 0
+	for _, terminal := range terminals {
+		if len(terminal) > width {
+			width = len(terminal)
+		}
+	}
+
+	fmt.Fprintf(w, "%*s", width, "")
+	for _, terminal := range terminals {
+		fmt.Fprintf(w, " %*s", width, terminal)
+	}
+	fmt.Fprintln(w)
+
+	for _, terminal1 := range terminals {
+		fmt.Fprintf(w, "%*s", width, terminal1)
+		for _, terminal2 := range terminals {
+			fmt.Fprintf(w, " %*s", width, precToString(matrix[terminal1][terminal2]))
+		}
+		fmt.Fprintln(w)
+	}
+}
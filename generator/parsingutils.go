@@ -1,6 +1,64 @@
 package generator
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
+
+/*
+position is a line and a column of a specification, both starting from 1.
+The column is counted in bytes.
+*/
+type position struct {
+	Line   int
+	Column int
+}
+
+/*
+source is a section of a specification file, which starts at the line firstLine of the file.
+It converts the offsets in the section to positions in the file.
+*/
+type source struct {
+	filename  string
+	bytes     []byte
+	firstLine int
+}
+
+/*
+newSource creates a source from the lines of a section of a specification.
+*/
+func newSource(filename string, lines []string, firstLine int) *source {
+	return &source{filename, []byte(strings.Join(lines, "\n")), firstLine}
+}
+
+/*
+Position returns the position in the file of an offset of the section.
+*/
+func (src *source) Position(offset int) position {
+	line := src.firstLine
+	lineStart := 0
+	for i := 0; i < offset && i < len(src.bytes); i++ {
+		if src.bytes[i] == '\n' {
+			line++
+			lineStart = i + 1
+		}
+	}
+	return position{line, offset - lineStart + 1}
+}
+
+/*
+Errorf returns a *SyntaxError located at an offset of the section.
+*/
+func (src *source) Errorf(offset int, format string, args ...interface{}) *SyntaxError {
+	return src.ErrorAt(offset, fmt.Errorf(format, args...))
+}
+
+/*
+ErrorAt returns a *SyntaxError that wraps err and is located at an offset of the section.
+*/
+func (src *source) ErrorAt(offset int, err error) *SyntaxError {
+	return newSyntaxError(src.filename, src.Position(offset), err)
+}
 
 func skipSpaces(bytes []byte, curPos int) int {
 	for curPos < len(bytes) && (bytes[curPos] == ' ' || bytes[curPos] == '\t' || bytes[curPos] == '\r' || bytes[curPos] == '\n') {
@@ -13,14 +71,19 @@ func getIdentifier(bytes []byte, curPos int) (string, int) {
 	startingPos := curPos
 	if curPos < len(bytes) && ((bytes[curPos] >= 'a' && bytes[curPos] <= 'z') || (bytes[curPos] >= 'A' && bytes[curPos] <= 'Z') || (bytes[curPos] == '_')) {
 		curPos++
-		for curPos < len(bytes) && ((bytes[curPos] >= 'a' && bytes[curPos] <= 'z') || (bytes[curPos] >= 'A' && bytes[curPos] <= 'Z') || (bytes[curPos] == '_') || (bytes[curPos] >= '9' && bytes[curPos] <= '0')) {
+		for curPos < len(bytes) && ((bytes[curPos] >= 'a' && bytes[curPos] <= 'z') || (bytes[curPos] >= 'A' && bytes[curPos] <= 'Z') || (bytes[curPos] == '_') || (bytes[curPos] >= '0' && bytes[curPos] <= '9')) {
 			curPos++
 		}
 	}
 	return string(bytes[startingPos:curPos]), curPos
 }
 
-func getSemanticFunction(bytes []byte, curPos int) (string, int) {
+/*
+getSemanticFunction reads the Go code of a semantic action, enclosed in braces,
+skipping the braces inside strings and comments.
+It returns false if the closing brace is missing.
+*/
+func getSemanticFunction(bytes []byte, curPos int) (string, int, bool) {
 	startingPos := curPos
 	numBraces := 0
 	for curPos < len(bytes) {
@@ -97,13 +160,13 @@ func getSemanticFunction(bytes []byte, curPos int) (string, int) {
 			numBraces--
 			if numBraces == 0 {
 				curPos++
-				break
+				return string(bytes[startingPos:curPos]), curPos, true
 			}
 		}
 		curPos++
 	}
 
-	return string(bytes[startingPos:curPos]), curPos
+	return string(bytes[startingPos:curPos]), curPos, false
 }
 
 func checkRegexpCompileError(err error) {
@@ -0,0 +1,260 @@
+package generator
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"strings"
+	"unicode"
+)
+
+/*
+generatedIdentifiers contains the package level identifiers declared by the emitted files,
+besides the tokens of the grammar.
+*/
+var generatedIdentifiers = []string{
+	"_NUM_NONTERMINALS", "_NUM_TERMINALS", "tokenToString", "TokenString",
+	"_MAX_RHS_LEN", "compressedTrie", "syncTerminals", "_PREC_MATRIX_BITPACKED",
+	"lexerAutomata", "cutPointsAutomaton", "lexerFunction", "function",
+	"Symbol", "Token", "symbol", "_ERROR", "_END_OF_FILE", "_LEX_CORRECT", "_SKIP",
+	"int64Pool", "newInt64Pool", "lexer", "grammar",
+	"lexerContext", "parserContext", "Parser", "NewParser",
+}
+
+/*
+literalFields contains the fields of a Symbol and of the Grammar and the Lexer of the runtime,
+which must not be renamed when they are used as keys of a composite literal.
+*/
+var literalFields = map[string]bool{
+	"Token":      true,
+	"Precedence": true,
+	"Value":      true,
+	"Next":       true,
+	"Child":      true,
+	"Offset":     true,
+	"Length":     true,
+
+	"NumTerminals":       true,
+	"MaxRHSLen":          true,
+	"Empty":              true,
+	"Term":               true,
+	"CompressedTrie":     true,
+	"PrecMatrix":         true,
+	"Function":           true,
+	"PreallocMem":        true,
+	"TokenString":        true,
+	"SyncTerminals":      true,
+	"Automata":           true,
+	"CutPointsAutomaton": true,
+}
+
+/*
+prefixer adds a prefix to the package level identifiers of a generated parser,
+so that several parsers can be generated in the same package.
+*/
+type prefixer struct {
+	prefix string
+	names  map[string]bool
+}
+
+/*
+newPrefixer creates a prefixer that renames the identifiers emitted by the generator,
+the tokens of the grammar and the package level identifiers declared in the code
+of the specifications.
+*/
+func newPrefixer(prefix string, nonterminals stringSet, terminals stringSet, code ...string) *prefixer {
+	p := &prefixer{prefix, make(map[string]bool)}
+
+	for _, name := range generatedIdentifiers {
+		p.names[name] = true
+	}
+	for _, token := range append(nonterminals, terminals...) {
+		p.names[token] = true
+		if !strings.HasPrefix(token, "_") {
+			p.names[exportedTokenName(token)] = true
+		}
+	}
+	for _, c := range code {
+		for _, name := range declaredIdentifiers(c) {
+			p.names[name] = true
+		}
+	}
+
+	return p
+}
+
+/*
+declaredIdentifiers returns the names declared at package level by a piece of Go code.
+Code that cannot be parsed is ignored, since the error is reported when the generated file is formatted.
+*/
+func declaredIdentifiers(code string) []string {
+	file, err := parser.ParseFile(token.NewFileSet(), "", "package p\n"+code, parser.SkipObjectResolution)
+
+	if err != nil {
+		return nil
+	}
+
+	names := make([]string, 0)
+
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			if decl.Recv == nil {
+				names = append(names, decl.Name.Name)
+			}
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				switch spec := spec.(type) {
+				case *ast.ValueSpec:
+					for _, name := range spec.Names {
+						names = append(names, name.Name)
+					}
+				case *ast.TypeSpec:
+					names = append(names, spec.Name.Name)
+				}
+			}
+		}
+	}
+
+	return names
+}
+
+/*
+declares returns true if a piece of Go code declares name at package level.
+*/
+func declares(code string, name string) bool {
+	for _, declared := range declaredIdentifiers(code) {
+		if declared == name {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+Rename returns the prefixed version of an identifier.
+Exported identifiers stay exported and unexported ones stay unexported:
+with the prefix expr, NewParser becomes ExprNewParser, lexerAutomata becomes
+exprLexerAutomata and _TERM becomes expr_TERM.
+*/
+func (p *prefixer) Rename(name string) string {
+	prefix := []rune(p.prefix)
+	first := []rune(name)[0]
+
+	if unicode.IsUpper(first) {
+		prefix[0] = unicode.ToUpper(prefix[0])
+		return string(prefix) + name
+	}
+
+	prefix[0] = unicode.ToLower(prefix[0])
+	if first == '_' {
+		return string(prefix) + name
+	}
+	return string(prefix) + string(unicode.ToUpper(first)) + name[len(string(first)):]
+}
+
+/*
+RenameFile returns the prefixed version of the name of a generated file.
+*/
+func (p *prefixer) RenameFile(name string) string {
+	return strings.ToLower(p.prefix) + "_" + name
+}
+
+/*
+RenameCode renames every occurrence of the identifiers of the prefixer in a piece of Go code.
+Selectors (such as the Token field in sym.Token) and the keys of a composite literal
+that are fields of Symbol, Grammar or Lexer (such as Token in symbol{Token: NUMBER}) are left untouched.
+The first word of a doc comment is renamed too when it is the name of the declaration that follows,
+as in "Parser parses..." above the declaration of Parser.
+*/
+func (p *prefixer) RenameCode(code []byte) []byte {
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(code))
+
+	var s scanner.Scanner
+	s.Init(file, code, nil, scanner.ScanComments)
+
+	type identifier struct {
+		offset int
+		name   string
+	}
+
+	//Collect the identifiers to rename, looking at the tokens around each one
+	toRename := make([]identifier, 0)
+	prevTok := token.ILLEGAL
+	var pending *identifier
+	//The first word of the last comment, renamed if the comment turns out to document a declaration of that name
+	var doc *identifier
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		if tok == token.COMMENT {
+			doc = nil
+			if offset, word := commentFirstWord(lit); word != "" {
+				doc = &identifier{file.Offset(pos) + offset, word}
+			}
+			continue
+		}
+
+		if doc != nil {
+			if tok == token.IDENT && lit == doc.name && p.names[lit] {
+				toRename = append(toRename, *doc)
+			}
+			if tok != token.FUNC && tok != token.TYPE && tok != token.VAR && tok != token.CONST {
+				doc = nil
+			}
+		}
+
+		if pending != nil {
+			if !(tok == token.COLON && literalFields[pending.name]) {
+				toRename = append(toRename, *pending)
+			}
+			pending = nil
+		}
+
+		if tok == token.IDENT && p.names[lit] && prevTok != token.PERIOD {
+			id := identifier{file.Offset(pos), lit}
+			if prevTok == token.LBRACE || prevTok == token.COMMA {
+				//It may be the key of a composite literal, wait for the next token
+				pending = &id
+			} else {
+				toRename = append(toRename, id)
+			}
+		}
+
+		prevTok = tok
+	}
+	if pending != nil {
+		toRename = append(toRename, *pending)
+	}
+
+	var sb strings.Builder
+	last := 0
+	for _, id := range toRename {
+		sb.Write(code[last:id.offset])
+		sb.WriteString(p.Rename(id.name))
+		last = id.offset + len(id.name)
+	}
+	sb.Write(code[last:])
+
+	return []byte(sb.String())
+}
+
+/*
+commentFirstWord returns the first word of a comment and its offset in the comment,
+or an empty word if the comment does not start with one.
+*/
+func commentFirstWord(comment string) (int, string) {
+	start := 2
+	for start < len(comment) && unicode.IsSpace(rune(comment[start])) {
+		start++
+	}
+	end := start
+	for end < len(comment) && (comment[end] == '_' || unicode.IsLetter(rune(comment[end])) || unicode.IsDigit(rune(comment[end]))) {
+		end++
+	}
+	return start, comment[start:end]
+}
@@ -1,5 +1,9 @@
 package regex
 
+import (
+	"sort"
+)
+
 const _EPSILON = 0
 
 type NfaState struct {
@@ -7,62 +11,6 @@ type NfaState struct {
 	AssociatedRules []int
 }
 
-func (state *NfaState) EpsilonClosure() []*NfaState {
-	closure := make([]*NfaState, 0)
-
-	closure = append(closure, state)
-
-	nextStateToCheckPos := 0
-
-	for nextStateToCheckPos < len(closure) {
-		curState := closure[nextStateToCheckPos]
-		closure = append(closure, curState.Transitions[_EPSILON]...)
-		nextStateToCheckPos++
-	}
-
-	return closure
-}
-
-func stateSetContains(stateSet []*NfaState, state *NfaState) bool {
-	for _, curState := range stateSet {
-		if curState == state {
-			return true
-		}
-	}
-	return false
-}
-
-func stateSetEpsilonClosure(stateSet []*NfaState) []*NfaState {
-	closure := make([]*NfaState, 0, len(stateSet))
-	for _, curState := range stateSet {
-		curEpsilonClosure := curState.EpsilonClosure()
-
-		for _, curClosureState := range curEpsilonClosure {
-			if !stateSetContains(closure, curClosureState) {
-				closure = append(closure, curClosureState)
-			}
-		}
-	}
-
-	return closure
-}
-
-func stateSetMove(stateSet []*NfaState, char int) []*NfaState {
-	states := make([]*NfaState, 0)
-
-	for _, curState := range stateSet {
-		reachedStates := curState.Transitions[char]
-
-		for _, curReachedState := range reachedStates {
-			if !stateSetContains(states, curReachedState) {
-				states = append(states, curReachedState)
-			}
-		}
-	}
-
-	return states
-}
-
 func (state *NfaState) AddTransition(char byte, ptr *NfaState) {
 	if state.Transitions[char] == nil {
 		state.Transitions[char] = []*NfaState{ptr}
@@ -216,6 +164,65 @@ func (nfa *Nfa) ZeroOrOne() {
 	nfa.NumStates += 2
 }
 
+/*
+repetition is the value of a counted quantifier: {Min}, {Min,} or {Min,Max}.
+Max is -1 if the number of repetitions is unbounded.
+*/
+type repetition struct {
+	Min int
+	Max int
+}
+
+//Operators {n}, {n,} and {n,m}
+func (nfa *Nfa) Repeat(min int, max int) {
+	original := nfa.clone()
+
+	result := NewEmptyStringNfa()
+
+	for i := 0; i < min; i++ {
+		result.Concatenate(original.clone())
+	}
+
+	if max < 0 {
+		rest := original.clone()
+		rest.KleeneStar()
+		result.Concatenate(rest)
+	} else {
+		for i := min; i < max; i++ {
+			rest := original.clone()
+			rest.ZeroOrOne()
+			result.Concatenate(rest)
+		}
+	}
+
+	*nfa = result
+}
+
+/*
+clone returns a copy of the automaton that shares no state with it.
+*/
+func (nfa *Nfa) clone() Nfa {
+	copies := make(map[*NfaState]*NfaState)
+
+	var cloneState func(state *NfaState) *NfaState
+	cloneState = func(state *NfaState) *NfaState {
+		if stateCopy, ok := copies[state]; ok {
+			return stateCopy
+		}
+		stateCopy := &NfaState{}
+		copies[state] = stateCopy
+		stateCopy.AssociatedRules = append([]int(nil), state.AssociatedRules...)
+		for char, nextStates := range state.Transitions {
+			for _, nextState := range nextStates {
+				stateCopy.Transitions[char] = append(stateCopy.Transitions[char], cloneState(nextState))
+			}
+		}
+		return stateCopy
+	}
+
+	return Nfa{cloneState(nfa.Initial), cloneState(nfa.Final), nfa.NumStates}
+}
+
 func (nfa *Nfa) AddAssociatedRule(ruleNum int) {
 	finalState := nfa.Final
 
@@ -226,83 +233,98 @@ func (nfa *Nfa) AddAssociatedRule(ruleNum int) {
 	}
 }
 
+/*
+ToDfa converts the automaton to an equivalent deterministic automaton with the least number of states.
+*/
 func (nfa *Nfa) ToDfa() Dfa {
-	genStates := make([]nfaStateSetPtr, 0)
+	dfa := nfa.SubsetConstruction()
+	return dfa.Minimize()
+}
 
-	curDfaStateNum := 0
+/*
+SubsetConstruction converts the automaton to an equivalent deterministic automaton,
+whose states are the sets of states of the automaton that can be reached by a string.
+The result is not minimized.
+*/
+func (nfa *Nfa) SubsetConstruction() Dfa {
+	indexed := newIndexedNfa(nfa)
+	final := indexed.Index[nfa.Final]
 
-	initialDfaState := DfaState{}
-	initialDfaState.Num = curDfaStateNum
+	dfa := Dfa{nil, make([]*DfaState, 0), 0}
 
-	dfa := Dfa{&initialDfaState, make([]*DfaState, 0), 1}
+	//The set of states of the automaton of each state of the dfa, sorted so that equal sets have the same key
+	stateSets := make([][]int, 0)
+	dfaStates := make([]*DfaState, 0)
+	dfaStateOfKey := make(map[string]*DfaState)
 
-	genStates = append(genStates, nfaStateSetPtr{nfa.Initial.EpsilonClosure(), &initialDfaState})
+	addDfaState := func(stateSet []int) *DfaState {
+		key := stateSetKey(stateSet)
+		if dfaState, ok := dfaStateOfKey[key]; ok {
+			return dfaState
+		}
 
-	search := func(gStates []nfaStateSetPtr, stateSet []*NfaState) *nfaStateSetPtr {
-		for _, curGState := range gStates {
-			if len(curGState.StateSet) != len(stateSet) {
-				continue
-			}
-			equal := true
-			for i, _ := range curGState.StateSet {
-				if curGState.StateSet[i] != stateSet[i] {
-					equal = false
-					break
-				}
-			}
-			if equal {
-				return &curGState
+		dfaState := &DfaState{}
+		dfaState.Num = len(stateSets)
+		dfaState.AssociatedRules = make([]int, 0)
+		for _, s := range stateSet {
+			dfaState.AssociatedRules = append(dfaState.AssociatedRules, indexed.States[s].AssociatedRules...)
+			if s == final {
+				dfaState.IsFinal = true
 			}
 		}
-		return nil
+		if dfaState.IsFinal {
+			dfa.Final = append(dfa.Final, dfaState)
+		}
+
+		dfaStateOfKey[key] = dfaState
+		stateSets = append(stateSets, stateSet)
+		dfaStates = append(dfaStates, dfaState)
+		return dfaState
 	}
 
-	nextStateToCheckPos := 0
+	dfa.Initial = addDfaState(indexed.Closures[indexed.Index[nfa.Initial]])
 
-	for nextStateToCheckPos < len(genStates) {
-		curStateSet := genStates[nextStateToCheckPos].StateSet
-		curDfaState := genStates[nextStateToCheckPos].Ptr
+	reached := newStateSet(len(indexed.States))
+	var charStates [256][]int
 
-		//For each character
-		for i := 1; i < 256; i++ {
-			charStateSet := stateSetMove(curStateSet, i)
-			epsilonClosure := stateSetEpsilonClosure(charStateSet)
+	for nextStateToCheckPos := 0; nextStateToCheckPos < len(stateSets); nextStateToCheckPos++ {
+		curStateSet := stateSets[nextStateToCheckPos]
+		curDfaState := dfaStates[nextStateToCheckPos]
+
+		//The states reached with each character
+		for _, s := range curStateSet {
+			for _, m := range indexed.Moves[s] {
+				charStates[m.Char] = append(charStates[m.Char], m.Next)
+			}
+		}
 
-			if len(epsilonClosure) == 0 {
+		//For each character, the union of the epsilon closures of the states reached with it
+		for i := 1; i < 256; i++ {
+			if len(charStates[i]) == 0 {
 				continue
 			}
 
-			foundStateSetPtr := search(genStates, epsilonClosure)
-
-			if foundStateSetPtr != nil {
-				curDfaState.Transitions[i] = foundStateSetPtr.Ptr
-			} else {
-				curDfaStateNum++
-				newDfaState := DfaState{}
-				newDfaState.Num = curDfaStateNum
-				newDfaState.AssociatedRules = make([]int, 0)
-				for _, curNfaState := range epsilonClosure {
-					newDfaState.AssociatedRules = append(newDfaState.AssociatedRules, curNfaState.AssociatedRules...)
+			var nextStateSet []int
+			for _, s := range charStates[i] {
+				for _, t := range indexed.Closures[s] {
+					if reached.Add(t) {
+						nextStateSet = append(nextStateSet, t)
+					}
 				}
-				curDfaState.Transitions[i] = &newDfaState
-				newStateSetPtr := nfaStateSetPtr{epsilonClosure, &newDfaState}
-
-				genStates = append(genStates, newStateSetPtr)
 			}
-		}
-		nextStateToCheckPos++
-	}
+			charStates[i] = charStates[i][:0]
 
-	dfa.NumStates = len(genStates)
+			for _, t := range nextStateSet {
+				reached.Remove(t)
+			}
+			sort.Ints(nextStateSet)
 
-	for _, genState := range genStates {
-		if stateSetContains(genState.StateSet, nfa.Final) {
-			finalState := genState.Ptr
-			finalState.IsFinal = true
-			dfa.Final = append(dfa.Final, finalState)
+			curDfaState.Transitions[i] = addDfaState(nextStateSet)
 		}
 	}
 
+	dfa.NumStates = len(stateSets)
+
 	return dfa
 }
 
@@ -376,20 +398,3 @@ func (dfa *Dfa) GetStates() []*DfaState {
 
 	return curState.IsFinal, true, curState.AssociatedTokens[index].Token
 }*/
-
-type nfaStateSetPtr struct {
-	StateSet []*NfaState
-	Ptr      *DfaState
-}
-
-func (stateSet1 *nfaStateSetPtr) Equals(stateSet2 *nfaStateSetPtr) bool {
-	if len(stateSet1.StateSet) != len(stateSet2.StateSet) {
-		return false
-	}
-	for i, _ := range stateSet1.StateSet {
-		if stateSet1.StateSet[i] != stateSet2.StateSet[i] {
-			return false
-		}
-	}
-	return true
-}
@@ -0,0 +1,310 @@
+package regex
+
+import (
+	"sort"
+	"unicode"
+	"unicode/utf8"
+)
+
+/*
+runeRange is a range of code points, including both ends.
+*/
+type runeRange struct {
+	Lo rune
+	Hi rune
+}
+
+/*
+setChar is a char of a set. CodePoint is true if it was written as a Unicode code point,
+either literally or with \u{...}, and false if it is a single byte.
+*/
+type setChar struct {
+	Value     rune
+	CodePoint bool
+}
+
+/*
+charSet is the set of the chars matched by a set or by a class.
+bytes contains the ASCII chars and the single bytes, while codePoints contains
+the code points above 0x7F, which are matched by their UTF-8 encoding.
+*/
+type charSet struct {
+	bytes      [256]bool
+	codePoints []runeRange
+}
+
+func newCharSet() *charSet {
+	return &charSet{}
+}
+
+/*
+newCharSetFromClass creates a set containing the bytes of a class.
+*/
+func newCharSetFromClass(chars [256]bool) *charSet {
+	return &charSet{bytes: chars}
+}
+
+/*
+newCharSetFromTable creates a set containing the code points of a Unicode range table.
+*/
+func newCharSetFromTable(table *unicode.RangeTable) *charSet {
+	set := newCharSet()
+	for _, r := range table.R16 {
+		for c := rune(r.Lo); c <= rune(r.Hi); c += rune(r.Stride) {
+			if r.Stride == 1 {
+				set.AddCodePoints(c, rune(r.Hi))
+				break
+			}
+			set.AddCodePoints(c, c)
+		}
+	}
+	for _, r := range table.R32 {
+		for c := rune(r.Lo); c <= rune(r.Hi); c += rune(r.Stride) {
+			if r.Stride == 1 {
+				set.AddCodePoints(c, rune(r.Hi))
+				break
+			}
+			set.AddCodePoints(c, c)
+		}
+	}
+	return set
+}
+
+/*
+AddChar adds a char to the set.
+*/
+func (set *charSet) AddChar(c setChar) {
+	set.AddRange(c, c)
+}
+
+/*
+AddRange adds the chars between lo and hi to the set. The range is made of code points
+if either end is a code point, and of bytes otherwise.
+*/
+func (set *charSet) AddRange(lo setChar, hi setChar) {
+	if lo.Value > hi.Value {
+		lo, hi = hi, lo
+	}
+
+	if lo.CodePoint || hi.CodePoint {
+		set.AddCodePoints(lo.Value, hi.Value)
+		return
+	}
+
+	for i := lo.Value; i <= hi.Value; i++ {
+		set.bytes[i] = true
+	}
+}
+
+/*
+AddCodePoints adds the code points between lo and hi to the set.
+The code points that cannot be encoded, such as the surrogates, are ignored.
+*/
+func (set *charSet) AddCodePoints(lo rune, hi rune) {
+	//Skip the first char (empty transition)
+	if lo < 1 {
+		lo = 1
+	}
+	if hi > unicode.MaxRune {
+		hi = unicode.MaxRune
+	}
+
+	for ; lo <= hi && lo < utf8.RuneSelf; lo++ {
+		set.bytes[lo] = true
+	}
+
+	if lo > hi {
+		return
+	}
+
+	//The surrogates have no UTF-8 encoding
+	const surrogateMin, surrogateMax = 0xD800, 0xDFFF
+	if lo <= surrogateMax && hi >= surrogateMin {
+		if lo < surrogateMin {
+			set.codePoints = append(set.codePoints, runeRange{lo, surrogateMin - 1})
+		}
+		if hi > surrogateMax {
+			set.codePoints = append(set.codePoints, runeRange{surrogateMax + 1, hi})
+		}
+		return
+	}
+
+	set.codePoints = append(set.codePoints, runeRange{lo, hi})
+}
+
+/*
+Add adds the chars of another set to the set.
+*/
+func (set *charSet) Add(other *charSet) {
+	for i, thereIs := range other.bytes {
+		if thereIs {
+			set.bytes[i] = true
+		}
+	}
+	set.codePoints = append(set.codePoints, other.codePoints...)
+}
+
+/*
+normalizedCodePoints returns the code points of the set as sorted ranges that do not overlap.
+*/
+func (set *charSet) normalizedCodePoints() []runeRange {
+	ranges := append([]runeRange(nil), set.codePoints...)
+	sort.Slice(ranges, func(i, j int) bool {
+		return ranges[i].Lo < ranges[j].Lo
+	})
+
+	merged := make([]runeRange, 0, len(ranges))
+	for _, r := range ranges {
+		if len(merged) > 0 && r.Lo <= merged[len(merged)-1].Hi+1 {
+			if r.Hi > merged[len(merged)-1].Hi {
+				merged[len(merged)-1].Hi = r.Hi
+			}
+			continue
+		}
+		merged = append(merged, r)
+	}
+
+	return merged
+}
+
+/*
+Negate returns the complement of the set. If codePoints is true, or if the set contains
+some code points above 0x7F, the complement contains every code point that is not in the set,
+matched by its UTF-8 encoding. Otherwise it contains every byte that is not in the set.
+*/
+func (set *charSet) Negate(codePoints bool) *charSet {
+	negated := newCharSet()
+
+	if !codePoints && len(set.codePoints) == 0 {
+		//Skip the first char (empty transition)
+		for i := 1; i < len(set.bytes); i++ {
+			negated.bytes[i] = !set.bytes[i]
+		}
+		return negated
+	}
+
+	for i := 1; i < utf8.RuneSelf; i++ {
+		negated.bytes[i] = !set.bytes[i]
+	}
+
+	next := rune(utf8.RuneSelf)
+	for _, r := range set.normalizedCodePoints() {
+		if r.Lo > next {
+			negated.AddCodePoints(next, r.Lo-1)
+		}
+		next = r.Hi + 1
+	}
+	negated.AddCodePoints(next, unicode.MaxRune)
+
+	return negated
+}
+
+/*
+Nfa returns an automaton that matches a char of the set.
+The code points are matched by a sequence of bytes, so the automaton has a state
+for each distinct prefix of their UTF-8 encodings.
+*/
+func (set *charSet) Nfa() Nfa {
+	nfa := Nfa{}
+	nfaInitial := NfaState{}
+	nfaFinal := NfaState{}
+
+	nfa.Initial = &nfaInitial
+	nfa.Final = &nfaFinal
+	nfa.NumStates = 2
+
+	for i, thereIs := range set.bytes {
+		if thereIs {
+			nfaInitial.AddTransition(byte(i), &nfaFinal)
+		}
+	}
+
+	//The states reached by a range of bytes from another state, to share the common prefixes
+	type prefixKey struct {
+		state *NfaState
+		lo    byte
+		hi    byte
+	}
+	prefixes := make(map[prefixKey]*NfaState)
+
+	sequences := make([][]byteRange, 0)
+	for _, r := range set.normalizedCodePoints() {
+		sequences = appendUtf8Sequences(sequences, r.Lo, r.Hi)
+	}
+
+	for _, seq := range sequences {
+		curState := &nfaInitial
+		for i, br := range seq {
+			var nextState *NfaState
+			if i == len(seq)-1 {
+				nextState = &nfaFinal
+			} else {
+				key := prefixKey{curState, br.Lo, br.Hi}
+				nextState = prefixes[key]
+				if nextState == nil {
+					nextState = &NfaState{}
+					prefixes[key] = nextState
+					nfa.NumStates++
+				} else {
+					curState = nextState
+					continue
+				}
+			}
+			for c := int(br.Lo); c <= int(br.Hi); c++ {
+				curState.AddTransition(byte(c), nextState)
+			}
+			curState = nextState
+		}
+	}
+
+	return nfa
+}
+
+/*
+byteRange is a range of bytes, including both ends.
+*/
+type byteRange struct {
+	Lo byte
+	Hi byte
+}
+
+/*
+appendUtf8Sequences appends to seqs the sequences of byte ranges that match the UTF-8 encodings
+of the code points between lo and hi, which must be above 0x7F and must not contain surrogates.
+The range is split until each part is made of code points whose encodings have the same length
+and differ in each byte by a range.
+*/
+func appendUtf8Sequences(seqs [][]byteRange, lo rune, hi rune) [][]byteRange {
+	//Split the range where the length of the encoding changes
+	for _, max := range []rune{0x7FF, 0xFFFF} {
+		if lo <= max && hi > max {
+			seqs = appendUtf8Sequences(seqs, lo, max)
+			return appendUtf8Sequences(seqs, max+1, hi)
+		}
+	}
+
+	//Split the range until the continuation bytes of its ends span their whole range
+	for i := uint(1); i < utf8.UTFMax; i++ {
+		m := rune(1)<<(6*i) - 1
+		if lo&^m != hi&^m {
+			if lo&m != 0 {
+				seqs = appendUtf8Sequences(seqs, lo, lo|m)
+				return appendUtf8Sequences(seqs, (lo|m)+1, hi)
+			}
+			if hi&m != m {
+				seqs = appendUtf8Sequences(seqs, lo, hi&^m-1)
+				return appendUtf8Sequences(seqs, hi&^m, hi)
+			}
+		}
+	}
+
+	loBytes := utf8.AppendRune(nil, lo)
+	hiBytes := utf8.AppendRune(nil, hi)
+
+	seq := make([]byteRange, len(loBytes))
+	for i := range loBytes {
+		seq[i] = byteRange{loBytes[i], hiBytes[i]}
+	}
+
+	return append(seqs, seq)
+}
@@ -0,0 +1,87 @@
+package regex
+
+/*
+charClass returns the set of the chars for which belongs returns true.
+The first char is never included, since it represents the empty transition.
+*/
+func charClass(belongs func(c byte) bool) [256]bool {
+	var chars [256]bool
+	for i := 1; i < len(chars); i++ {
+		chars[i] = belongs(byte(i))
+	}
+	return chars
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isUpper(c byte) bool {
+	return c >= 'A' && c <= 'Z'
+}
+
+func isLower(c byte) bool {
+	return c >= 'a' && c <= 'z'
+}
+
+func isAlpha(c byte) bool {
+	return isUpper(c) || isLower(c)
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == '\v'
+}
+
+func isPrint(c byte) bool {
+	return c >= ' ' && c <= '~'
+}
+
+/*
+posixClasses contains the classes that may be written as [:name:] inside a set.
+*/
+var posixClasses = map[string]func(c byte) bool{
+	"alpha":  isAlpha,
+	"digit":  isDigit,
+	"alnum":  func(c byte) bool { return isAlpha(c) || isDigit(c) },
+	"upper":  isUpper,
+	"lower":  isLower,
+	"space":  isSpace,
+	"blank":  func(c byte) bool { return c == ' ' || c == '\t' },
+	"punct":  func(c byte) bool { return isPrint(c) && c != ' ' && !isAlpha(c) && !isDigit(c) },
+	"xdigit": func(c byte) bool { return isDigit(c) || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F') },
+	"cntrl":  func(c byte) bool { return c < ' ' || c == 0x7f },
+	"print":  isPrint,
+	"graph":  func(c byte) bool { return isPrint(c) && c != ' ' },
+}
+
+/*
+shorthandClass returns the class of a shorthand escape: \d, \w or \s.
+Their negations \D, \W and \S return the same class, which must be negated.
+It returns false if c does not name a shorthand class.
+*/
+func shorthandClass(c byte) ([256]bool, bool) {
+	switch c {
+	case 'd', 'D':
+		return charClass(isDigit), true
+	case 'w', 'W':
+		return charClass(func(c byte) bool { return isAlpha(c) || isDigit(c) || c == '_' }), true
+	case 's', 'S':
+		return charClass(isSpace), true
+	}
+	return [256]bool{}, false
+}
+
+/*
+hexValue returns the value of a hexadecimal digit, or -1 if c is not one.
+*/
+func hexValue(c byte) int {
+	switch {
+	case isDigit(c):
+		return int(c - '0')
+	case c >= 'a' && c <= 'f':
+		return int(c-'a') + 10
+	case c >= 'A' && c <= 'F':
+		return int(c-'A') + 10
+	}
+	return -1
+}
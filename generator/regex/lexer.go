@@ -1,30 +1,38 @@
+//go:generate go run ../../cmd/papageno generate -grammar parser/regex.g -out . -pkg regex
+
 package regex
 
 import (
 	"errors"
-)
-
-const (
-	_ERROR       = -1
-	_END_OF_FILE = 0
-	_LEX_CORRECT = 1
+	"unicode"
+	"unicode/utf8"
 )
 
 /*
-lexer contains the file data and the current position.
+Options contains the parameters of the parsing of a regular expression.
 */
-type lexer struct {
-	data []byte
-	pos  int
+type Options struct {
+	//UTF8 makes ., the negated sets and the negated shorthand classes match whole
+	//UTF-8 encoded code points instead of single bytes.
+	UTF8 bool
 }
 
 /*
-lexerPreallocMem initializes all the memory pools required by the lexer.
+lexer contains the file data, the current position, the options of the parsing
+and whether the current position is inside a set.
 */
-func lexerPreallocMem(inputSize int, numThreads int) {
+type lexer struct {
+	data      []byte
+	pos       int
+	opts      Options
+	insideSet bool
 }
 
-var insideSet bool = false
+/*
+maxRepetitions is the largest count allowed in a counted quantifier,
+since the automaton contains a copy of the repeated expression for each count.
+*/
+const maxRepetitions = 1000
 
 /*
 yyLex reads a token from the lexer and creates a new symbol, saving it in genSym.
@@ -41,105 +49,295 @@ func (l *lexer) yyLex(genSym *symbol) int {
 
 		switch {
 		case curChar == '(':
-			*genSym = symbol{lpar, 0, nil, nil, nil}
+			*genSym = symbol{Token: lpar}
 			return _LEX_CORRECT
 		case curChar == ')':
-			*genSym = symbol{rpar, 0, nil, nil, nil}
+			*genSym = symbol{Token: rpar}
+			return _LEX_CORRECT
+		case curChar == '[' && l.insideSet && l.pos < len(l.data) && l.data[l.pos] == ':':
+			//A POSIX class such as [:alpha:]
+			end := l.pos + 1
+			for end < len(l.data) && isLower(l.data[end]) {
+				end++
+			}
+			belongs, ok := posixClasses[string(l.data[l.pos+1:end])]
+			if !ok || end+1 >= len(l.data) || l.data[end] != ':' || l.data[end+1] != ']' {
+				return _ERROR
+			}
+			l.pos = end + 2
+			*genSym = symbol{Token: classinset, Value: newCharSetFromClass(charClass(belongs))}
 			return _LEX_CORRECT
 		case curChar == '[':
-			insideSet = true
-			*genSym = symbol{squarelpar, 0, nil, nil, nil}
+			l.insideSet = true
+			*genSym = symbol{Token: squarelpar}
 			return _LEX_CORRECT
 		case curChar == ']':
-			insideSet = false
-			*genSym = symbol{squarerpar, 0, nil, nil, nil}
+			l.insideSet = false
+			*genSym = symbol{Token: squarerpar}
 			return _LEX_CORRECT
 		case curChar == '*':
-			*genSym = symbol{star, 0, nil, nil, nil}
+			*genSym = symbol{Token: star}
 			return _LEX_CORRECT
 		case curChar == '+':
-			*genSym = symbol{plus, 0, nil, nil, nil}
+			*genSym = symbol{Token: plus}
 			return _LEX_CORRECT
 		case curChar == '-':
-			*genSym = symbol{dash, 0, nil, nil, nil}
+			*genSym = symbol{Token: dash}
 			return _LEX_CORRECT
 		case curChar == '|':
-			*genSym = symbol{pipe, 0, nil, nil, nil}
+			*genSym = symbol{Token: pipe}
 			return _LEX_CORRECT
 		case curChar == '^':
-			*genSym = symbol{caret, 0, nil, nil, nil}
+			//The caret tells the negated sets whether they contain code points or bytes
+			*genSym = symbol{Token: caret, Value: l.opts.UTF8}
 			return _LEX_CORRECT
-		case curChar == '.':
-			var anyCharClass [256]bool
-			//Skip the first char (empty transition)
-			for i := 1; i < len(anyCharClass); i++ {
-				anyCharClass[i] = true
+		case curChar == '{' && !l.insideSet:
+			//A brace starts a counted quantifier, or is an ordinary char otherwise
+			r, newPos, ok := lexRepetition(l.data, l.pos)
+			if !ok {
+				*genSym = symbol{Token: char, Value: curChar}
+				return _LEX_CORRECT
 			}
-			anyCharClass['\n'] = false
-			anyCharClass['\r'] = false
-			newNfa := newNfaFromCharClass(anyCharClass)
-			*genSym = symbol{any, 0, &newNfa, nil, nil}
+			if (r.Max >= 0 && r.Max < r.Min) || r.Min > maxRepetitions || r.Max > maxRepetitions {
+				return _ERROR
+			}
+			l.pos = newPos
+			*genSym = symbol{Token: repeat, Value: r}
+			return _LEX_CORRECT
+		case curChar == '.':
+			newlines := newCharSet()
+			newlines.AddChar(setChar{'\n', false})
+			newlines.AddChar(setChar{'\r', false})
+			newNfa := newlines.Negate(l.opts.UTF8).Nfa()
+			*genSym = symbol{Token: any, Value: &newNfa}
 			return _LEX_CORRECT
+		case curChar >= utf8.RuneSelf && utf8.FullRune(l.data[l.pos-1:]):
+			//A literal code point is matched as a whole, while an invalid byte is an ordinary char
+			r, size := utf8.DecodeRune(l.data[l.pos-1:])
+			if r == utf8.RuneError && size == 1 {
+				return l.lexChar(genSym, setChar{rune(curChar), false})
+			}
+			l.pos += size - 1
+			return l.lexChar(genSym, setChar{r, true})
 		case curChar == '\t' || curChar == '\r' || curChar == '\n':
 			//Do nothing
 		case curChar == '\\':
+			if l.pos >= len(l.data) {
+				return _ERROR
+			}
 			escapedChar := l.data[l.pos]
 			l.pos++
-			switch escapedChar {
-			case '(', ')', '[', ']', '*', '+', '-', '|', '^', '.', '\\':
-				token := uint16(char)
-				if insideSet {
-					token = charinset
+
+			//The classes are a set item inside a set, and behave like . outside
+			var class *charSet
+			if chars, ok := shorthandClass(escapedChar); ok {
+				class = newCharSetFromClass(chars)
+				if isUpper(escapedChar) {
+					//The negations of the shorthand classes
+					class = class.Negate(l.opts.UTF8)
 				}
-				*genSym = symbol{token, 0, escapedChar, nil, nil}
-				return _LEX_CORRECT
-			case 't':
-				token := uint16(char)
-				if insideSet {
-					token = charinset
+			} else if escapedChar == 'p' || escapedChar == 'P' {
+				table, newPos, ok := lexUnicodeClass(l.data, l.pos)
+				if !ok {
+					return _ERROR
 				}
-				*genSym = symbol{token, 0, byte('\t'), nil, nil}
-				return _LEX_CORRECT
-			case 'r':
-				token := uint16(char)
-				if insideSet {
-					token = charinset
+				l.pos = newPos
+				class = newCharSetFromTable(table)
+				if escapedChar == 'P' {
+					class = class.Negate(true)
+				}
+			}
+			if class != nil {
+				if l.insideSet {
+					*genSym = symbol{Token: classinset, Value: class}
+				} else {
+					newNfa := class.Nfa()
+					*genSym = symbol{Token: any, Value: &newNfa}
 				}
-				*genSym = symbol{token, 0, byte('\r'), nil, nil}
 				return _LEX_CORRECT
+			}
+
+			var value byte
+			switch escapedChar {
+			case '(', ')', '[', ']', '{', '}', '*', '+', '-', '|', '^', '.', '\\':
+				value = escapedChar
+			case 't':
+				value = '\t'
+			case 'r':
+				value = '\r'
 			case 'n':
-				token := uint16(char)
-				if insideSet {
-					token = charinset
+				value = '\n'
+			case 'f':
+				value = '\f'
+			case 'v':
+				value = '\v'
+			case 'x':
+				//A byte written as two hexadecimal digits
+				if l.pos+1 >= len(l.data) || hexValue(l.data[l.pos]) < 0 || hexValue(l.data[l.pos+1]) < 0 {
+					return _ERROR
 				}
-				*genSym = symbol{token, 0, byte('\n'), nil, nil}
-				return _LEX_CORRECT
+				value = byte(hexValue(l.data[l.pos])<<4 | hexValue(l.data[l.pos+1]))
+				l.pos += 2
+			case '0', '1', '2', '3':
+				//A byte written as three octal digits
+				if l.pos+1 >= len(l.data) || l.data[l.pos] < '0' || l.data[l.pos] > '7' || l.data[l.pos+1] < '0' || l.data[l.pos+1] > '7' {
+					return _ERROR
+				}
+				value = (escapedChar-'0')<<6 | (l.data[l.pos]-'0')<<3 | (l.data[l.pos+1] - '0')
+				l.pos += 2
+			case 'u':
+				//A code point written as \u{...} with hexadecimal digits
+				r, newPos, ok := lexCodePoint(l.data, l.pos)
+				if !ok {
+					return _ERROR
+				}
+				l.pos = newPos
+				return l.lexChar(genSym, setChar{r, true})
 			default:
 				return _ERROR
 			}
-		default:
-			token := uint16(char)
-			if insideSet {
-				token = charinset
+
+			//The byte 0 cannot be matched, since it represents the empty transition
+			if value == 0 {
+				return _ERROR
 			}
-			*genSym = symbol{token, 0, curChar, nil, nil}
-			return _LEX_CORRECT
+
+			return l.lexChar(genSym, setChar{rune(value), false})
+		default:
+			return l.lexChar(genSym, setChar{rune(curChar), false})
 		}
 	}
 	return _END_OF_FILE
 }
 
 /*
-lex reads an input string as a slice of byte and lexes it, pushing each symbol in a listOfStacks.
-It returns a listOfStacks containing all the lexed symbols.
+lexChar creates the symbol of a char: a set item inside a set, or otherwise a char
+if it is a single byte and an automaton matching its UTF-8 encoding if it is a code point.
+*/
+func (l *lexer) lexChar(genSym *symbol, c setChar) int {
+	switch {
+	case l.insideSet:
+		*genSym = symbol{Token: charinset, Value: c}
+	case c.CodePoint && c.Value >= utf8.RuneSelf:
+		set := newCharSet()
+		set.AddChar(c)
+		newNfa := set.Nfa()
+		*genSym = symbol{Token: any, Value: &newNfa}
+	default:
+		*genSym = symbol{Token: char, Value: byte(c.Value)}
+	}
+	return _LEX_CORRECT
+}
+
+/*
+lexCodePoint reads the braces and the hexadecimal digits of a \u{...} escape, starting after the u.
+It returns the code point and the position following the escape, or false if the escape is invalid.
+*/
+func lexCodePoint(data []byte, pos int) (rune, int, bool) {
+	if pos >= len(data) || data[pos] != '{' {
+		return 0, pos, false
+	}
+	pos++
+
+	var r rune
+	startingPos := pos
+	for pos < len(data) && hexValue(data[pos]) >= 0 && pos-startingPos < 6 {
+		r = r<<4 | rune(hexValue(data[pos]))
+		pos++
+	}
+
+	if pos == startingPos || pos >= len(data) || data[pos] != '}' || r == 0 || !utf8.ValidRune(r) {
+		return 0, pos, false
+	}
+
+	return r, pos + 1, true
+}
+
+/*
+lexUnicodeClass reads the name of a Unicode class of a \p or \P escape, starting after the p.
+The name is either a letter, as in \pL, or enclosed in braces, as in \p{Greek},
+and is a general category or a script.
+It returns the table of the class and the position following the escape, or false if the class does not exist.
+*/
+func lexUnicodeClass(data []byte, pos int) (*unicode.RangeTable, int, bool) {
+	if pos >= len(data) {
+		return nil, pos, false
+	}
+
+	var name string
+	if data[pos] == '{' {
+		end := pos + 1
+		for end < len(data) && data[end] != '}' {
+			end++
+		}
+		if end >= len(data) {
+			return nil, pos, false
+		}
+		name = string(data[pos+1 : end])
+		pos = end + 1
+	} else {
+		name = string(data[pos : pos+1])
+		pos++
+	}
+
+	if table, ok := unicode.Categories[name]; ok {
+		return table, pos, true
+	}
+	if table, ok := unicode.Scripts[name]; ok {
+		return table, pos, true
+	}
+
+	return nil, pos, false
+}
+
+/*
+lexRepetition reads the rest of a counted quantifier, starting after its opening brace.
+It returns the quantifier and the position following it, or false if the brace does not start one.
+*/
+func lexRepetition(data []byte, pos int) (repetition, int, bool) {
+	readNumber := func() (int, bool) {
+		startingPos := pos
+		num := 0
+		for pos < len(data) && data[pos] >= '0' && data[pos] <= '9' {
+			num = num*10 + int(data[pos]-'0')
+			pos++
+		}
+		return num, pos > startingPos
+	}
+
+	min, ok := readNumber()
+	if !ok {
+		return repetition{}, pos, false
+	}
+
+	max := min
+	if pos < len(data) && data[pos] == ',' {
+		pos++
+		var bounded bool
+		max, bounded = readNumber()
+		if !bounded {
+			max = -1
+		}
+	}
+
+	if pos >= len(data) || data[pos] != '}' {
+		return repetition{}, pos, false
+	}
+
+	return repetition{min, max}, pos + 1, true
+}
+
+/*
+lex reads an input string as a slice of byte and lexes it.
+It returns a slice containing all the lexed symbols.
 An error is returned if the string contains invalid data.
 */
-func lex(input []byte, stackPool *stackPool) (listOfStacks, error) {
-	los := newLos(stackPool)
+func lex(input []byte, opts Options) ([]Symbol, error) {
+	symbols := make([]Symbol, 0)
 
 	sym := symbol{}
 
-	lexer := lexer{input, 0}
+	lexer := lexer{input, 0, opts, false}
 
 	//Lex the first symbol
 	res := lexer.yyLex(&sym)
@@ -147,12 +345,62 @@ func lex(input []byte, stackPool *stackPool) (listOfStacks, error) {
 	//Keep lexing until the end of the file is reached or an error occurs
 	for res != _END_OF_FILE {
 		if res == _ERROR {
-			return los, errors.New("Lexing error")
+			return nil, errors.New("Lexing error")
 		}
-		los.Push(&sym)
+		symbols = append(symbols, sym)
 
 		res = lexer.yyLex(&sym)
 	}
 
-	return los, nil
+	return symbols, nil
+}
+
+/*
+ParseString parses a regular expression using numThreads threads.
+It returns the symbol at the root of the syntactic tree, whose value is the corresponding *Nfa.
+*/
+func ParseString(str []byte, numThreads int) (*Symbol, error) {
+	return ParseStringWithOptions(str, numThreads, Options{})
+}
+
+/*
+ParseStringWithOptions parses a regular expression like ParseString, using the given options.
+*/
+func ParseStringWithOptions(str []byte, numThreads int, opts Options) (*Symbol, error) {
+	//A regular expression made only of letters, digits and underscores, such as a keyword,
+	//matches itself, so it does not need to be parsed
+	if isWord(str) {
+		newNfa := newNfaFromString(str)
+		return &Symbol{Value: &newNfa}, nil
+	}
+
+	symbols, err := lex(str, opts)
+
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := NewParser().ParseTokens(symbols, numThreads)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if root == nil {
+		return nil, errors.New("the regular expression is empty")
+	}
+
+	return root, nil
+}
+
+/*
+isWord returns true if str is not empty and is made only of ASCII letters, digits and underscores.
+*/
+func isWord(str []byte) bool {
+	for _, c := range str {
+		if !isAlpha(c) && !isDigit(c) && c != '_' {
+			return false
+		}
+	}
+	return len(str) > 0
 }
@@ -0,0 +1,177 @@
+package regex
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+/*
+Minimize returns the deterministic automaton with the least number of states that is equivalent to dfa,
+using the partition refinement of Hopcroft.
+Two states are merged only if they are both final or both not final and have the same AssociatedRules,
+so the minimal automaton still tells which lexer rules match.
+The states that cannot reach a final state are removed, and the initial state keeps the number 0.
+*/
+func (dfa *Dfa) Minimize() Dfa {
+	states := dfa.GetStates()
+
+	//The missing transitions lead to an additional dead state
+	numStates := len(states) + 1
+	dead := len(states)
+
+	target := func(s int, c int) int {
+		if s == dead || states[s].Transitions[c] == nil {
+			return dead
+		}
+		return states[s].Transitions[c].Num
+	}
+
+	//inverse[c][t] contains the states that reach t with the char c
+	inverse := make([][][]int, 256)
+	for c := 1; c < 256; c++ {
+		inverse[c] = make([][]int, numStates)
+		for s := 0; s < numStates; s++ {
+			t := target(s, c)
+			inverse[c][t] = append(inverse[c][t], s)
+		}
+	}
+
+	//The initial partition groups the states by their finality and their rules
+	block := make([]int, numStates)
+	blocks := make([][]int, 0)
+	blockOfKey := make(map[string]int)
+	for s := 0; s < numStates; s++ {
+		key := stateKey(&DfaState{})
+		if s != dead {
+			key = stateKey(states[s])
+		}
+		b, ok := blockOfKey[key]
+		if !ok {
+			b = len(blocks)
+			blockOfKey[key] = b
+			blocks = append(blocks, nil)
+		}
+		block[s] = b
+		blocks[b] = append(blocks[b], s)
+	}
+
+	work := make([]int, len(blocks))
+	inWork := make([]bool, len(blocks))
+	for b := range blocks {
+		work[b] = b
+		inWork[b] = true
+	}
+
+	for len(work) > 0 {
+		a := work[len(work)-1]
+		work = work[:len(work)-1]
+		inWork[a] = false
+
+		splitter := append([]int(nil), blocks[a]...)
+
+		for c := 1; c < 256; c++ {
+			//Group by block the states that reach the splitter with c
+			touched := make([]int, 0)
+			predecessors := make(map[int][]int)
+			for _, t := range splitter {
+				for _, s := range inverse[c][t] {
+					b := block[s]
+					if _, ok := predecessors[b]; !ok {
+						touched = append(touched, b)
+					}
+					predecessors[b] = append(predecessors[b], s)
+				}
+			}
+
+			//Split each block that only partly reaches the splitter
+			for _, b := range touched {
+				inSplitter := predecessors[b]
+				if len(inSplitter) == len(blocks[b]) {
+					continue
+				}
+
+				newBlock := len(blocks)
+				for _, s := range inSplitter {
+					block[s] = newBlock
+				}
+				rest := make([]int, 0, len(blocks[b])-len(inSplitter))
+				for _, s := range blocks[b] {
+					if block[s] == b {
+						rest = append(rest, s)
+					}
+				}
+				blocks[b] = rest
+				blocks = append(blocks, inSplitter)
+				inWork = append(inWork, false)
+
+				if inWork[b] || len(inSplitter) <= len(rest) {
+					work = append(work, newBlock)
+					inWork[newBlock] = true
+				} else {
+					work = append(work, b)
+					inWork[b] = true
+				}
+			}
+		}
+	}
+
+	//If no string is accepted the automaton only keeps its initial state
+	if block[0] == block[dead] {
+		initial := &DfaState{}
+		return Dfa{initial, make([]*DfaState, 0), 1}
+	}
+
+	//Number the blocks by their first state, dropping the one of the dead state
+	order := make([]int, 0, len(blocks))
+	for b, members := range blocks {
+		if b != block[dead] {
+			sort.Ints(members)
+			order = append(order, b)
+		}
+	}
+	sort.Slice(order, func(i, j int) bool {
+		return blocks[order[i]][0] < blocks[order[j]][0]
+	})
+
+	newStates := make([]*DfaState, len(blocks))
+	for num, b := range order {
+		rep := states[blocks[b][0]]
+		newStates[b] = &DfaState{
+			Num:             num,
+			IsFinal:         rep.IsFinal,
+			AssociatedRules: append([]int(nil), rep.AssociatedRules...),
+		}
+	}
+
+	minimized := Dfa{newStates[block[0]], make([]*DfaState, 0), len(order)}
+
+	for _, b := range order {
+		rep := blocks[b][0]
+		for c := 1; c < 256; c++ {
+			if t := target(rep, c); block[t] != block[dead] {
+				newStates[b].Transitions[c] = newStates[block[t]]
+			}
+		}
+		if newStates[b].IsFinal {
+			minimized.Final = append(minimized.Final, newStates[b])
+		}
+	}
+
+	return minimized
+}
+
+/*
+stateKey returns a string that identifies the finality and the rules of a state.
+*/
+func stateKey(state *DfaState) string {
+	rules := append([]int(nil), state.AssociatedRules...)
+	sort.Ints(rules)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%t", state.IsFinal)
+	for _, r := range rules {
+		fmt.Fprintf(&sb, ",%d", r)
+	}
+	return sb.String()
+}
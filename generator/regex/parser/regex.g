@@ -1,7 +1,7 @@
 /*
 parserPreallocMem initializes all the memory pools required by the semantic function.
 */
-func parserPreallocMem(inputSize int, numThreads int) {
+func parserPreallocMem(ctx *parserContext, inputSize int, numThreads int) {
 }
 %%
 
@@ -38,19 +38,14 @@ SIMPLE_RE : CONCATENATION
 	$$.Value = &newNfa
 } | squarelpar SET_ITEMS squarerpar
 {
-	newNfa := newNfaFromCharClass($2.Value.([256]bool))
+	newNfa := $2.Value.(*charSet).Nfa()
 	
 	$$.Value = &newNfa
 } | squarelpar caret SET_ITEMS squarerpar
 {
-	chars := $3.Value.([256]bool)
+	chars := $3.Value.(*charSet).Negate($2.Value.(bool))
 	
-	//Skip the first char (empty transition)
-	for i := 1; i < len(chars); i++ {
-		chars[i] = !chars[i]
-	}
-	
-	newNfa := newNfaFromCharClass(chars)
+	newNfa := chars.Nfa()
 	
 	$$.Value = &newNfa
 } | lpar RE rpar
@@ -70,20 +65,15 @@ SIMPLE_RE : CONCATENATION
 	$$.Value = &newNfa
 } | squarelpar SET_ITEMS squarerpar star
 {
-	newNfa := newNfaFromCharClass($2.Value.([256]bool))
+	newNfa := $2.Value.(*charSet).Nfa()
 	newNfa.KleeneStar()
 	
 	$$.Value = &newNfa
 } | squarelpar caret SET_ITEMS squarerpar star
 {
-	chars := $3.Value.([256]bool)
-	
-	//Skip the first char (empty transition)
-	for i := 1; i < len(chars); i++ {
-		chars[i] = !chars[i]
-	}
+	chars := $3.Value.(*charSet).Negate($2.Value.(bool))
 	
-	newNfa := newNfaFromCharClass(chars)
+	newNfa := chars.Nfa()
 	
 	newNfa.KleeneStar()
 	
@@ -109,20 +99,15 @@ SIMPLE_RE : CONCATENATION
 	$$.Value = &newNfa
 } | squarelpar SET_ITEMS squarerpar plus
 {
-	newNfa := newNfaFromCharClass($2.Value.([256]bool))
+	newNfa := $2.Value.(*charSet).Nfa()
 	newNfa.KleenePlus()
 	
 	$$.Value = &newNfa
 } | squarelpar caret SET_ITEMS squarerpar plus
 {
-	chars := $3.Value.([256]bool)
-	
-	//Skip the first char (empty transition)
-	for i := 1; i < len(chars); i++ {
-		chars[i] = !chars[i]
-	}
+	chars := $3.Value.(*charSet).Negate($2.Value.(bool))
 	
-	newNfa := newNfaFromCharClass(chars)
+	newNfa := chars.Nfa()
 	
 	newNfa.KleenePlus()
 	
@@ -133,6 +118,45 @@ SIMPLE_RE : CONCATENATION
 	
 	nfaEnclosed.KleenePlus()
 	
+	$$.Value = nfaEnclosed
+} | any repeat
+{
+	nfaAny := $1.Value.(*Nfa)
+	r := $2.Value.(repetition)
+	nfaAny.Repeat(r.Min, r.Max)
+	
+	$$.Value = nfaAny
+} | char repeat
+{
+	newNfa := newNfaFromChar($1.Value.(byte))
+	r := $2.Value.(repetition)
+	newNfa.Repeat(r.Min, r.Max)
+	
+	$$.Value = &newNfa
+} | squarelpar SET_ITEMS squarerpar repeat
+{
+	newNfa := $2.Value.(*charSet).Nfa()
+	r := $4.Value.(repetition)
+	newNfa.Repeat(r.Min, r.Max)
+	
+	$$.Value = &newNfa
+} | squarelpar caret SET_ITEMS squarerpar repeat
+{
+	chars := $3.Value.(*charSet).Negate($2.Value.(bool))
+	
+	newNfa := chars.Nfa()
+	
+	r := $5.Value.(repetition)
+	newNfa.Repeat(r.Min, r.Max)
+	
+	$$.Value = &newNfa
+} | lpar RE rpar repeat
+{
+	nfaEnclosed := $2.Value.(*Nfa)
+	
+	r := $4.Value.(repetition)
+	nfaEnclosed.Repeat(r.Min, r.Max)
+	
 	$$.Value = nfaEnclosed
 };
 
@@ -155,7 +179,7 @@ CONCATENATION : any SIMPLE_RE
 	$$.Value = &newNfa
 } | squarelpar SET_ITEMS squarerpar SIMPLE_RE
 {
-	newNfa := newNfaFromCharClass($2.Value.([256]bool))
+	newNfa := $2.Value.(*charSet).Nfa()
 	rightNfa := $4.Value.(*Nfa)
 	
 	newNfa.Concatenate(*rightNfa)
@@ -163,14 +187,9 @@ CONCATENATION : any SIMPLE_RE
 	$$.Value = &newNfa
 } | squarelpar caret SET_ITEMS squarerpar SIMPLE_RE
 {
-	chars := $3.Value.([256]bool)
-	
-	//Skip the first char (empty transition)
-	for i := 1; i < len(chars); i++ {
-		chars[i] = !chars[i]
-	}
+	chars := $3.Value.(*charSet).Negate($2.Value.(bool))
 	
-	newNfa := newNfaFromCharClass(chars)
+	newNfa := chars.Nfa()
 	rightNfa := $5.Value.(*Nfa)
 	
 	newNfa.Concatenate(*rightNfa)
@@ -197,14 +216,14 @@ CONCATENATION : any SIMPLE_RE
 {
 	newNfa := newNfaFromChar($1.Value.(byte))
 	newNfa.KleeneStar()
-	rightNfa := $2.Value.(*Nfa)
+	rightNfa := $3.Value.(*Nfa)
 
 	newNfa.Concatenate(*rightNfa)
 	
 	$$.Value = &newNfa
 } | squarelpar SET_ITEMS squarerpar star SIMPLE_RE
 {
-	newNfa := newNfaFromCharClass($2.Value.([256]bool))
+	newNfa := $2.Value.(*charSet).Nfa()
 	newNfa.KleeneStar()
 	rightNfa := $5.Value.(*Nfa)
 	
@@ -213,14 +232,9 @@ CONCATENATION : any SIMPLE_RE
 	$$.Value = &newNfa
 } | squarelpar caret SET_ITEMS squarerpar star SIMPLE_RE
 {
-	chars := $3.Value.([256]bool)
+	chars := $3.Value.(*charSet).Negate($2.Value.(bool))
 	
-	//Skip the first char (empty transition)
-	for i := 1; i < len(chars); i++ {
-		chars[i] = !chars[i]
-	}
-	
-	newNfa := newNfaFromCharClass(chars)
+	newNfa := chars.Nfa()
 	newNfa.KleeneStar()
 	rightNfa := $6.Value.(*Nfa)
 	
@@ -249,14 +263,14 @@ CONCATENATION : any SIMPLE_RE
 {
 	newNfa := newNfaFromChar($1.Value.(byte))
 	newNfa.KleenePlus()
-	rightNfa := $2.Value.(*Nfa)
+	rightNfa := $3.Value.(*Nfa)
 
 	newNfa.Concatenate(*rightNfa)
 	
 	$$.Value = &newNfa
 } | squarelpar SET_ITEMS squarerpar plus SIMPLE_RE
 {
-	newNfa := newNfaFromCharClass($2.Value.([256]bool))
+	newNfa := $2.Value.(*charSet).Nfa()
 	newNfa.KleenePlus()
 	rightNfa := $5.Value.(*Nfa)
 	
@@ -265,14 +279,9 @@ CONCATENATION : any SIMPLE_RE
 	$$.Value = &newNfa
 } | squarelpar caret SET_ITEMS squarerpar plus SIMPLE_RE
 {
-	chars := $3.Value.([256]bool)
+	chars := $3.Value.(*charSet).Negate($2.Value.(bool))
 	
-	//Skip the first char (empty transition)
-	for i := 1; i < len(chars); i++ {
-		chars[i] = !chars[i]
-	}
-	
-	newNfa := newNfaFromCharClass(chars)
+	newNfa := chars.Nfa()
 	newNfa.KleenePlus()
 	rightNfa := $6.Value.(*Nfa)
 	
@@ -287,52 +296,91 @@ CONCATENATION : any SIMPLE_RE
 	
 	nfaEnclosed.Concatenate(*rightNfa)
 		
+	$$.Value = nfaEnclosed
+} | any repeat SIMPLE_RE
+{
+	nfaAny := $1.Value.(*Nfa)
+	r := $2.Value.(repetition)
+	nfaAny.Repeat(r.Min, r.Max)
+	rightNfa := $3.Value.(*Nfa)
+	
+	nfaAny.Concatenate(*rightNfa)
+	
+	$$.Value = nfaAny
+} | char repeat SIMPLE_RE
+{
+	newNfa := newNfaFromChar($1.Value.(byte))
+	r := $2.Value.(repetition)
+	newNfa.Repeat(r.Min, r.Max)
+	rightNfa := $3.Value.(*Nfa)
+
+	newNfa.Concatenate(*rightNfa)
+	
+	$$.Value = &newNfa
+} | squarelpar SET_ITEMS squarerpar repeat SIMPLE_RE
+{
+	newNfa := $2.Value.(*charSet).Nfa()
+	r := $4.Value.(repetition)
+	newNfa.Repeat(r.Min, r.Max)
+	rightNfa := $5.Value.(*Nfa)
+	
+	newNfa.Concatenate(*rightNfa)
+	
+	$$.Value = &newNfa
+} | squarelpar caret SET_ITEMS squarerpar repeat SIMPLE_RE
+{
+	chars := $3.Value.(*charSet).Negate($2.Value.(bool))
+	
+	newNfa := chars.Nfa()
+	r := $5.Value.(repetition)
+	newNfa.Repeat(r.Min, r.Max)
+	rightNfa := $6.Value.(*Nfa)
+	
+	newNfa.Concatenate(*rightNfa)
+	
+	$$.Value = &newNfa
+} | lpar RE rpar repeat SIMPLE_RE
+{
+	nfaEnclosed := $2.Value.(*Nfa)
+	r := $4.Value.(repetition)
+	nfaEnclosed.Repeat(r.Min, r.Max)
+	rightNfa := $5.Value.(*Nfa)
+	
+	nfaEnclosed.Concatenate(*rightNfa)
+	
 	$$.Value = nfaEnclosed
 };
 SET_ITEMS : charinset
 {
-	var charSet [256]bool
-	charSet[$1.Value.(byte)] = true
+	set := newCharSet()
+	set.AddChar($1.Value.(setChar))
 	
-	$$.Value = charSet
+	$$.Value = set
 } | charinset dash charinset
 {
-	charStart := $1.Value.(byte)
-	charEnd := $3.Value.(byte)
-	
-	if charStart > charEnd {
-		temp := charStart
-		charStart = charEnd
-		charEnd = temp
-	}
-	
-	var charSet [256]bool
-	for i := charStart; i <= charEnd; i++ {
-		charSet[i] = true
-	}
+	set := newCharSet()
+	set.AddRange($1.Value.(setChar), $3.Value.(setChar))
 	
-	$$.Value = charSet
+	$$.Value = set
 } | charinset SET_ITEMS
 {
-	charSet := $2.Value.([256]bool)
-	charSet[$1.Value.(byte)] = true
+	set := $2.Value.(*charSet)
+	set.AddChar($1.Value.(setChar))
 	
-	$$.Value = charSet
+	$$.Value = set
 } | charinset dash charinset SET_ITEMS
 {
-	charStart := $1.Value.(byte)
-	charEnd := $3.Value.(byte)
-	charSet := $4.Value.([256]bool)
+	set := $4.Value.(*charSet)
+	set.AddRange($1.Value.(setChar), $3.Value.(setChar))
 	
-	if charStart > charEnd {
-		temp := charStart
-		charStart = charEnd
-		charEnd = temp
-	}
-	
-	for i := charStart; i <= charEnd; i++ {
-		charSet[i] = true
-	}
+	$$.Value = set
+} | classinset
+{
+	$$.Value = $1.Value
+} | classinset SET_ITEMS
+{
+	set := $2.Value.(*charSet)
+	set.Add($1.Value.(*charSet))
 	
-	$$.Value = charSet
+	$$.Value = set
 };
\ No newline at end of file
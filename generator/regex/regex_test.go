@@ -0,0 +1,263 @@
+package regex
+
+import (
+	"testing"
+)
+
+/*
+matches returns true if the automaton accepts the whole string.
+*/
+func matches(dfa Dfa, str string) bool {
+	state := dfa.Initial
+	for i := 0; i < len(str); i++ {
+		state = state.Transitions[str[i]]
+		if state == nil {
+			return false
+		}
+	}
+	return state.IsFinal
+}
+
+func TestCountedRepetition(t *testing.T) {
+	tests := []struct {
+		regex    string
+		accepted []string
+		rejected []string
+	}{
+		{"a{3}", []string{"aaa"}, []string{"", "aa", "aaaa"}},
+		{"a{2,}", []string{"aa", "aaa", "aaaaaaa"}, []string{"", "a"}},
+		{"a{1,3}", []string{"a", "aa", "aaa"}, []string{"", "aaaa"}},
+		{"a{0}b", []string{"b"}, []string{"ab"}},
+		{"[0-9]{4}\\-[0-9]{2}", []string{"2017-04"}, []string{"201-04", "2017-4"}},
+		{"(ab){2}c", []string{"ababc"}, []string{"abc", "abababc"}},
+		{"[^a]{2,3}x", []string{"bcx", "bcdx"}, []string{"bx", "bcdex", "bax"}},
+		{".{2}", []string{"ab"}, []string{"a"}},
+		{"a*b", []string{"b", "aab"}, []string{"a"}},
+		{"a{b", []string{"a{b"}, []string{"ab"}},
+		{"\\{1\\}", []string{"{1}"}, []string{"1"}},
+	}
+
+	for _, test := range tests {
+		result, err := ParseString([]byte(test.regex), 1)
+		if err != nil {
+			t.Errorf("Error: %s could not be parsed: %s", test.regex, err.Error())
+			continue
+		}
+
+		dfa := result.Value.(*Nfa).ToDfa()
+
+		for _, str := range test.accepted {
+			if !matches(dfa, str) {
+				t.Errorf("Error: %s should accept %q", test.regex, str)
+			}
+		}
+		for _, str := range test.rejected {
+			if matches(dfa, str) {
+				t.Errorf("Error: %s should not accept %q", test.regex, str)
+			}
+		}
+	}
+
+	for _, regex := range []string{"a{3,2}", "a{1001}"} {
+		if _, err := ParseString([]byte(regex), 1); err == nil {
+			t.Errorf("Error: %s should not be parsed", regex)
+		}
+	}
+}
+
+func TestCharClassesAndEscapes(t *testing.T) {
+	tests := []struct {
+		regex    string
+		accepted []string
+		rejected []string
+	}{
+		{"\\d+", []string{"0", "123"}, []string{"", "a"}},
+		{"\\D", []string{"a", " "}, []string{"1"}},
+		{"\\w+", []string{"a_1Z"}, []string{"a-b"}},
+		{"\\W", []string{"-"}, []string{"a", "_"}},
+		{"\\s\\S", []string{" a", "\tb"}, []string{"  ", "ab"}},
+		{"[\\d_]+", []string{"1_2"}, []string{"a"}},
+		{"[^\\s]", []string{"a"}, []string{" ", "\n"}},
+		{"\\x41\\x7a", []string{"Az"}, []string{"AZ"}},
+		{"[\\x41-\\x43]", []string{"A", "C"}, []string{"D"}},
+		{"[\\x80-\\xff]", []string{"\x80", "\xff"}, []string{"a"}},
+		{"\\101\\012", []string{"A\n"}, []string{"A"}},
+		{"[[:alpha:]_][[:alnum:]_]*", []string{"_a1", "Z"}, []string{"1a"}},
+		{"[[:xdigit:]]{2}", []string{"fF", "09"}, []string{"fg"}},
+		{"[^[:space:][:punct:]]", []string{"a", "1"}, []string{" ", "."}},
+		{"\\f\\v", []string{"\f\v"}, []string{"fv"}},
+	}
+
+	for _, test := range tests {
+		result, err := ParseString([]byte(test.regex), 1)
+		if err != nil {
+			t.Errorf("Error: %s could not be parsed: %s", test.regex, err.Error())
+			continue
+		}
+
+		dfa := result.Value.(*Nfa).ToDfa()
+
+		for _, str := range test.accepted {
+			if !matches(dfa, str) {
+				t.Errorf("Error: %s should accept %q", test.regex, str)
+			}
+		}
+		for _, str := range test.rejected {
+			if matches(dfa, str) {
+				t.Errorf("Error: %s should not accept %q", test.regex, str)
+			}
+		}
+	}
+
+	for _, regex := range []string{"\\x4", "\\xg0", "\\x00", "\\000", "\\09", "[[:nothing:]]", "[[:alpha]", "\\q"} {
+		if _, err := ParseString([]byte(regex), 1); err == nil {
+			t.Errorf("Error: %s should not be parsed", regex)
+		}
+	}
+}
+
+func TestUnicode(t *testing.T) {
+	tests := []struct {
+		regex    string
+		utf8     bool
+		accepted []string
+		rejected []string
+	}{
+		{"é+", false, []string{"é", "ééé"}, []string{"e", "\xc3"}},
+		{"[àé]", false, []string{"à", "é"}, []string{"a", "\xc3"}},
+		{"[α-ω]+", false, []string{"αβγ"}, []string{"ABC", "Ω"}},
+		{"\\u{3b1}\\u{1F600}", false, []string{"α😀"}, []string{"α"}},
+		{"[\\u{41}-\\u{43}]", false, []string{"A", "C"}, []string{"D"}},
+		{"\\p{L}+", false, []string{"abc", "Ωμέγα", "日本語"}, []string{"1", "_", "😀"}},
+		{"\\pL\\pN", false, []string{"a1", "ä٣"}, []string{"aa"}},
+		{"\\p{Greek}+", false, []string{"αβγ"}, []string{"abc"}},
+		{"[\\p{Lu}_]", false, []string{"A", "Ä", "_"}, []string{"a", "ä"}},
+		{"\\P{L}", false, []string{"1", "😀"}, []string{"a", "é", "\xff"}},
+		{"[^é]", false, []string{"e", "ü", "😀"}, []string{"é", "\xff"}},
+		{"[^a]", false, []string{"b", "\xff"}, []string{"a", "é"}},
+		{".", false, []string{"a", "\xff"}, []string{"é", "\n"}},
+		{".", true, []string{"a", "é", "😀"}, []string{"\xff", "\n", "ab"}},
+		{"[^a]", true, []string{"é"}, []string{"a", "\xff"}},
+		{"\\W", true, []string{"é", "-"}, []string{"a", "\xff"}},
+		{"\\xff", true, []string{"\xff"}, []string{"ÿ"}},
+	}
+
+	for _, test := range tests {
+		result, err := ParseStringWithOptions([]byte(test.regex), 1, Options{UTF8: test.utf8})
+		if err != nil {
+			t.Errorf("Error: %s could not be parsed: %s", test.regex, err.Error())
+			continue
+		}
+
+		dfa := result.Value.(*Nfa).ToDfa()
+
+		for _, str := range test.accepted {
+			if !matches(dfa, str) {
+				t.Errorf("Error: %s (utf8 %v) should accept %q", test.regex, test.utf8, str)
+			}
+		}
+		for _, str := range test.rejected {
+			if matches(dfa, str) {
+				t.Errorf("Error: %s (utf8 %v) should not accept %q", test.regex, test.utf8, str)
+			}
+		}
+	}
+
+	for _, regex := range []string{"\\u{}", "\\u{110000}", "\\u{D800}", "\\u41", "\\p{Nothing}", "\\p{L"} {
+		if _, err := ParseString([]byte(regex), 1); err == nil {
+			t.Errorf("Error: %s should not be parsed", regex)
+		}
+	}
+}
+
+func TestSubsetConstruction(t *testing.T) {
+	tests := []struct {
+		regex    string
+		accepted []string
+		rejected []string
+	}{
+		//Epsilon cycles
+		{"(a*)*b", []string{"b", "aab"}, []string{"", "ba"}},
+		{"((a|b)*)+c", []string{"c", "abbac"}, []string{"ab"}},
+		//Literal words, which are not parsed
+		{"while", []string{"while"}, []string{"whil", "whilee"}},
+		{"x_1", []string{"x_1"}, []string{"x1"}},
+	}
+
+	for _, test := range tests {
+		result, err := ParseString([]byte(test.regex), 1)
+		if err != nil {
+			t.Errorf("Error: %s could not be parsed: %s", test.regex, err.Error())
+			continue
+		}
+
+		dfa := result.Value.(*Nfa).SubsetConstruction()
+
+		if len(dfa.GetStates()) != dfa.NumStates {
+			t.Errorf("Error: the states of the automaton of %s are not numbered correctly", test.regex)
+		}
+		for _, str := range test.accepted {
+			if !matches(dfa, str) {
+				t.Errorf("Error: %s should accept %q", test.regex, str)
+			}
+		}
+		for _, str := range test.rejected {
+			if matches(dfa, str) {
+				t.Errorf("Error: %s should not accept %q", test.regex, str)
+			}
+		}
+	}
+}
+
+func TestMinimize(t *testing.T) {
+	result, err := ParseString([]byte("(a|b)*abb"), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nfa := result.Value.(*Nfa)
+	dfa := nfa.SubsetConstruction()
+	minimized := dfa.Minimize()
+
+	if minimized.NumStates != 4 || dfa.NumStates <= minimized.NumStates {
+		t.Errorf("Error: the automaton has %d states, minimized to %d, should be 4", dfa.NumStates, minimized.NumStates)
+	}
+	if minimized.Initial.Num != 0 || len(minimized.GetStates()) != minimized.NumStates {
+		t.Errorf("Error: the states of the minimized automaton are not numbered correctly")
+	}
+	for _, str := range []string{"abb", "aabb", "babb"} {
+		if !matches(minimized, str) {
+			t.Errorf("Error: the minimized automaton should accept %q", str)
+		}
+	}
+
+	//The final states of different rules must not be merged
+	buildLexer := func(sameRule bool) Dfa {
+		var lexerNfa *Nfa
+		for i, regex := range []string{"ab", "cb"} {
+			result, err := ParseString([]byte(regex), 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ruleNfa := result.Value.(*Nfa)
+			if sameRule {
+				ruleNfa.AddAssociatedRule(0)
+			} else {
+				ruleNfa.AddAssociatedRule(i)
+			}
+			if lexerNfa == nil {
+				lexerNfa = ruleNfa
+			} else {
+				lexerNfa.Unite(*ruleNfa)
+			}
+		}
+		return lexerNfa.ToDfa()
+	}
+
+	if states := buildLexer(true).NumStates; states != 3 {
+		t.Errorf("Error: the automaton of a single rule has %d states, should be 3", states)
+	}
+	if states := buildLexer(false).NumStates; states != 5 {
+		t.Errorf("Error: the automaton of two rules has %d states, should be 5", states)
+	}
+}
@@ -0,0 +1,145 @@
+package regex
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+/*
+stateSet is a set of states of an automaton, as a bitset of the indices of the states.
+*/
+type stateSet []uint64
+
+func newStateSet(numStates int) stateSet {
+	return make(stateSet, (numStates+63)/64)
+}
+
+/*
+Add adds the state with index i to the set, returning false if it was already there.
+*/
+func (set stateSet) Add(i int) bool {
+	word, bit := i/64, uint64(1)<<(uint(i)%64)
+	if set[word]&bit != 0 {
+		return false
+	}
+	set[word] |= bit
+	return true
+}
+
+/*
+Remove removes the state with index i from the set.
+*/
+func (set stateSet) Remove(i int) {
+	set[i/64] &^= uint64(1) << (uint(i) % 64)
+}
+
+/*
+Indices returns the indices of the states of the set in increasing order.
+*/
+func (set stateSet) Indices() []int {
+	indices := make([]int, 0)
+	for w, word := range set {
+		for word != 0 {
+			indices = append(indices, w*64+bits.TrailingZeros64(word))
+			word &= word - 1
+		}
+	}
+	return indices
+}
+
+/*
+stateSetKey returns a string that identifies a sorted slice of indices of states,
+to be used as the key of a map.
+*/
+func stateSetKey(indices []int) string {
+	key := make([]byte, 0, len(indices)*4)
+	for _, i := range indices {
+		key = binary.LittleEndian.AppendUint32(key, uint32(i))
+	}
+	return string(key)
+}
+
+/*
+move is a transition of an automaton that reads a character, leading to the state with index Next.
+*/
+type move struct {
+	Char byte
+	Next int
+}
+
+/*
+indexedNfa contains the states of an automaton numbered in the order they are reached from the initial one,
+together with their transitions and their epsilon closures.
+*/
+type indexedNfa struct {
+	States []*NfaState
+	Index  map[*NfaState]int
+	//Moves contains the transitions of each state that read a character
+	Moves [][]move
+	//Closures contains the sorted indices of the epsilon closure of each state
+	Closures [][]int
+}
+
+func newIndexedNfa(nfa *Nfa) *indexedNfa {
+	indexed := &indexedNfa{make([]*NfaState, 0, nfa.NumStates), make(map[*NfaState]int, nfa.NumStates), nil, nil}
+
+	indexed.Index[nfa.Initial] = 0
+	indexed.States = append(indexed.States, nfa.Initial)
+	for nextStateToCheckPos := 0; nextStateToCheckPos < len(indexed.States); nextStateToCheckPos++ {
+		for _, nextStates := range indexed.States[nextStateToCheckPos].Transitions {
+			for _, nextState := range nextStates {
+				if _, ok := indexed.Index[nextState]; !ok {
+					indexed.Index[nextState] = len(indexed.States)
+					indexed.States = append(indexed.States, nextState)
+				}
+			}
+		}
+	}
+
+	//The final state may be unreachable, as in the automaton of an empty set
+	if _, ok := indexed.Index[nfa.Final]; !ok {
+		indexed.Index[nfa.Final] = len(indexed.States)
+		indexed.States = append(indexed.States, nfa.Final)
+	}
+
+	indexed.Moves = make([][]move, len(indexed.States))
+	indexed.Closures = make([][]int, len(indexed.States))
+	visited := newStateSet(len(indexed.States))
+	for i, state := range indexed.States {
+		for c := 1; c < 256; c++ {
+			for _, nextState := range state.Transitions[c] {
+				indexed.Moves[i] = append(indexed.Moves[i], move{byte(c), indexed.Index[nextState]})
+			}
+		}
+		indexed.Closures[i] = indexed.epsilonClosure(i, visited)
+	}
+
+	return indexed
+}
+
+/*
+epsilonClosure returns the sorted indices of the states reachable from the state with index i
+with epsilon transitions only, including the state itself.
+closure must be an empty set, and it is emptied again before returning.
+*/
+func (indexed *indexedNfa) epsilonClosure(i int, closure stateSet) []int {
+	closure.Add(i)
+
+	toCheck := []int{i}
+	for len(toCheck) > 0 {
+		curState := indexed.States[toCheck[len(toCheck)-1]]
+		toCheck = toCheck[:len(toCheck)-1]
+		for _, nextState := range curState.Transitions[_EPSILON] {
+			if next := indexed.Index[nextState]; closure.Add(next) {
+				toCheck = append(toCheck, next)
+			}
+		}
+	}
+
+	indices := closure.Indices()
+	for _, s := range indices {
+		closure.Remove(s)
+	}
+
+	return indices
+}
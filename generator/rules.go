@@ -5,10 +5,17 @@ import (
 	"strings"
 )
 
+/*
+rule is a rule of the grammar. Origin is the index of the rule of the specification
+a rewritten rule derives from, or -1 if the rule was added by the generator.
+Pos is the position of the rule in the grammar specification; the rewritten rules have none.
+*/
 type rule struct {
 	LHS    string
 	RHS    []string
 	Action string
+	Origin int
+	Pos    position
 }
 
 func (r rule) String() string {
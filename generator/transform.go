@@ -5,22 +5,24 @@ import (
 	"strings"
 )
 
-func deleteRepeatedRHS(nonterminals stringSet, terminals stringSet, axiom string, rules []rule) ([]rule, stringSet) {
+func deleteRepeatedRHS(nonterminals stringSet, terminals stringSet, axiom string, rules []rule, d *diagnostics) ([]rule, stringSet) {
 	newRules := make([]rule, 0)
 
 	dictRules := createNewDictRules()
 
+	//The semantic actions are tracked by pointer, so they also identify the rule each new rule derives from
+	origins := make(map[*string]int)
+
 	for i, rule := range rules {
 		dictRules.Add(rule.RHS, rule.LHS, &rules[i].Action)
+		origins[&rules[i].Action] = i
 	}
 
-	fmt.Println("Old dictRules:")
-	fmt.Println(dictRules)
+	d.Dump("old dictRules", dictRules)
 
 	updatedDictRules := deleteCopyRules(nonterminals, rules, dictRules)
 
-	fmt.Println("Updated dictRules:")
-	fmt.Println(updatedDictRules)
+	d.Dump("updated dictRules", updatedDictRules)
 
 	V := updatedDictRules.ToValueLHSSets()
 	//fmt.Println("ValueLHSSets:")
@@ -114,8 +116,7 @@ func deleteRepeatedRHS(nonterminals stringSet, terminals stringSet, axiom string
 		}
 	}
 
-	fmt.Println("New dictRules:")
-	fmt.Println(newDictRules)
+	d.Dump("new dictRules", newDictRules)
 
 	//Create the rules from dictRules
 	for i, _ := range newDictRules.KeysRHS {
@@ -123,7 +124,12 @@ func deleteRepeatedRHS(nonterminals stringSet, terminals stringSet, axiom string
 		valueLHS := newDictRules.ValuesLHS[i]
 		semAction := newDictRules.SemActions[i]
 
-		newRules = append(newRules, rule{strings.Join(valueLHS, "_"), keyRHS, *semAction})
+		origin, ok := origins[semAction]
+		if !ok {
+			origin = -1
+		}
+
+		newRules = append(newRules, rule{strings.Join(valueLHS, "_"), keyRHS, *semAction, origin, position{}})
 	}
 
 	newNonterminalSet, _ := inferTokens(newRules)
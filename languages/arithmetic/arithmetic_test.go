@@ -0,0 +1,143 @@
+package arithmetic
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/simoneguidi94/gopapageno/papageno"
+)
+
+/*
+TestConcurrentParsers parses different expressions at the same time with a Parser for each goroutine,
+checking that the memory pools of the semantic functions are not shared between them.
+*/
+func TestConcurrentParsers(t *testing.T) {
+	var wg sync.WaitGroup
+
+	for i := 1; i <= 8; i++ {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+
+			parser := NewParser()
+
+			//The sum of n lines containing n, so that the result is n*n
+			expression := strings.TrimSuffix(strings.Repeat(fmt.Sprintf("%d +\n", n), n), " +\n")
+
+			for j := 0; j < 20; j++ {
+				root, err := parser.ParseString([]byte(expression), 2)
+
+				if err != nil {
+					t.Errorf("Error: %s", err.Error())
+					return
+				}
+				if result := *root.Value.(*int64); result != int64(n*n) {
+					t.Errorf("Error: the result of the sum of %d times %d is %d", n, n, result)
+					return
+				}
+			}
+		}(i)
+	}
+
+	wg.Wait()
+}
+
+/*
+TestParserReuse parses inputs of different sizes with the same Parser,
+checking that its memory pools and the ones of its semantic functions grow when needed and are kept otherwise.
+*/
+func TestParserReuse(t *testing.T) {
+	parser := NewParser()
+
+	small := []byte("1 + 2 * 3")
+	large := []byte(strings.TrimSuffix(strings.Repeat("1 +\n", 200000), " +\n"))
+
+	tests := []struct {
+		input    []byte
+		expected int64
+	}{
+		{small, 7},
+		{large, 200000},
+		{small, 7},
+		{small, 7},
+	}
+
+	sizes := make([]int, len(tests))
+	int64Pools := make([]*papageno.Int64Pool, len(tests))
+	remainders := make([]int, len(tests))
+
+	for i, test := range tests {
+		root, err := parser.ParseString(test.input, 2)
+
+		if err != nil {
+			t.Fatalf("Error: %s", err.Error())
+		}
+		if result := *root.Value.(*int64); result != test.expected {
+			t.Errorf("Error: the result of parse %d is %d, should be %d", i, result, test.expected)
+		}
+
+		sizes[i] = parser.Stats().StackPoolSizes[0]
+		int64Pools[i] = parser.parserCtx.int64Pools[0]
+		remainders[i] = int64Pools[i].Remainder()
+		parser.Reset()
+	}
+
+	//The third parse may grow the pool further, by the stacks that were missing from it in the large parse
+	if sizes[1] <= sizes[0] || sizes[2] < sizes[1] || sizes[3] != sizes[2] {
+		t.Errorf("Error: the sizes of the stack pools are %v, they should grow and then be kept", sizes)
+	}
+	if int64Pools[1] == int64Pools[0] || int64Pools[2] != int64Pools[1] {
+		t.Errorf("Error: the pool of the semantic function should grow and then be kept")
+	}
+	//The pool is reused from its beginning, so more items are left after the small parse than after the large one
+	if remainders[2] <= remainders[1] {
+		t.Errorf("Error: the pool of the semantic function has %v items left after each parse, it was not reused", remainders)
+	}
+
+	parser.Release()
+
+	if parser.parserCtx.int64Pools != nil {
+		t.Errorf("Error: the pools of the semantic function were not dropped by Release")
+	}
+
+	if _, err := parser.ParseString(small, 2); err != nil {
+		t.Fatalf("Error: %s", err.Error())
+	}
+	if size := parser.Stats().StackPoolSizes[0]; size != sizes[0] {
+		t.Errorf("Error: the stack pool has %d stacks after Release, should have %d", size, sizes[0])
+	}
+}
+
+/*
+TestStackPoolGrowth parses deeply nested parentheses, which need more stacks than the ones estimated from the length of the input,
+checking that the missing stacks are reported by the stats and added to the pool of the next parse.
+*/
+func TestStackPoolGrowth(t *testing.T) {
+	parser := NewParser()
+
+	nested := []byte(strings.Repeat("(", 50000) + "1" + strings.Repeat(")", 50000))
+
+	if _, err := parser.ParseString(nested, 1); err != nil {
+		t.Fatalf("Error: %s", err.Error())
+	}
+
+	size := parser.Stats().StackPoolSizes[0]
+	missing := -parser.Stats().RemainingStacks[0]
+
+	if missing <= 0 {
+		t.Fatalf("Error: %d stacks are left in the pool, the input should need more stacks than the pool has", -missing)
+	}
+
+	if _, err := parser.ParseString(nested, 1); err != nil {
+		t.Fatalf("Error: %s", err.Error())
+	}
+
+	if grown := parser.Stats().StackPoolSizes[0]; grown != size+missing {
+		t.Errorf("Error: the stack pool has %d stacks after the growth, should have %d", grown, size+missing)
+	}
+	if remaining := parser.Stats().RemainingStacks[0]; remaining < 0 {
+		t.Errorf("Error: %d stacks are still missing from the pool", -remaining)
+	}
+}
@@ -71,6 +71,10 @@ func main() {
 	defer parseParallelCodeFile.Close()
 	parseParallelCodeFile.Write([]byte("1, 0\n"))
 
+	//The garbage collector is run before each parse, so that it does not affect the measured times
+	parser := arithmetic.NewParser()
+	parser.SetForceGC(true)
+
 	for i := 0; i < *numThreads; i++ {
 		curNumThreads := i + 1
 
@@ -86,20 +90,20 @@ func main() {
 		for j := 0; j < *numTests; j++ {
 			fmt.Printf("Test n° %d:\n", j+1)
 
-			root, err := arithmetic.ParseFile(*fname, curNumThreads)
+			root, err := parser.ParseFile(*fname, curNumThreads)
 
 			runtime.GC()
 
 			if err == nil {
 				fmt.Println("Parse succeded!")
-				fmt.Printf("Time to alloc memory: %s\n", arithmetic.Stats.AllocMemTime)
-				fmt.Printf("Time to lex: %s\n", arithmetic.Stats.LexTimeTotal)
-				fmt.Printf("Time to parse: %s\n\n", arithmetic.Stats.ParseTimeTotal)
+				fmt.Printf("Time to alloc memory: %s\n", parser.Stats().AllocMemTime)
+				fmt.Printf("Time to lex: %s\n", parser.Stats().LexTimeTotal)
+				fmt.Printf("Time to parse: %s\n\n", parser.Stats().ParseTimeTotal)
 				fmt.Printf("Result: %d\n", *root.Value.(*int64))
 
-				memAllocTimes[j] = arithmetic.Stats.AllocMemTime
-				lexTimes[j] = arithmetic.Stats.LexTimeTotal
-				parseTimes[j] = arithmetic.Stats.ParseTimeTotal
+				memAllocTimes[j] = parser.Stats().AllocMemTime
+				lexTimes[j] = parser.Stats().LexTimeTotal
+				parseTimes[j] = parser.Stats().ParseTimeTotal
 			} else {
 				//This should not happen
 				fmt.Println("Parse failed!")
@@ -1,30 +1,41 @@
+// Code generated by papageno from arith.l and arith.g. DO NOT EDIT.
+
 package arithmetic
 
 import (
 	"math"
 )
 
-var parserInt64Pools []*int64Pool
+/*
+parserContext contains the memory pools of the semantic function of a Parser.
+*/
+type parserContext struct {
+	int64Pools []*int64Pool
+}
 
 /*
 parserPreallocMem initializes all the memory pools required by the semantic function of the parser.
 */
-func parserPreallocMem(inputSize int, numThreads int) {
-	parserInt64Pools = make([]*int64Pool, numThreads)
-	
+func parserPreallocMem(ctx *parserContext, inputSize int, numThreads int) {
+	//The pools of the previous parse are reused if they are large enough
+	for len(ctx.int64Pools) < numThreads {
+		ctx.int64Pools = append(ctx.int64Pools, nil)
+	}
+
 	avgCharsPerNumber := float64(4)
-	
+
 	poolSizePerThread := int(math.Ceil((float64(inputSize) / avgCharsPerNumber) / float64(numThreads)))
 
 	for i := 0; i < numThreads; i++ {
-		parserInt64Pools[i] = newInt64Pool(poolSizePerThread)
+		ctx.int64Pools[i] = ctx.int64Pools[i].Reuse(poolSizePerThread)
 	}
 }
 
 /*
 function is the semantic function of the parser.
+ctx is the parserContext of the Parser, initialized by parserPreallocMem before each parse.
 */
-func function(thread int, ruleNum uint16, lhs *symbol, rhs []*symbol) {
+func function(ctx *parserContext, thread int, ruleNum uint16, lhs *Symbol, rhs []*Symbol) {
 	switch ruleNum {
 	case 0:
 		NEW_AXIOM0 := lhs
@@ -46,7 +57,7 @@ func function(thread int, ruleNum uint16, lhs *symbol, rhs []*symbol) {
 		PLUS2.Next = E_F_S_T3
 
 		{
-			newValue := parserInt64Pools[thread].Get()
+			newValue := ctx.int64Pools[thread].Get()
 			*newValue = *E_F_S_T1.Value.(*int64) + *E_F_S_T3.Value.(*int64)
 			E_S0.Value = newValue
 		}
@@ -61,7 +72,7 @@ func function(thread int, ruleNum uint16, lhs *symbol, rhs []*symbol) {
 		PLUS2.Next = E_S_T3
 
 		{
-			newValue := parserInt64Pools[thread].Get()
+			newValue := ctx.int64Pools[thread].Get()
 			*newValue = *E_F_S_T1.Value.(*int64) + *E_S_T3.Value.(*int64)
 			E_S0.Value = newValue
 		}
@@ -76,7 +87,7 @@ func function(thread int, ruleNum uint16, lhs *symbol, rhs []*symbol) {
 		TIMES2.Next = E_F_S_T3
 
 		{
-			newValue := parserInt64Pools[thread].Get()
+			newValue := ctx.int64Pools[thread].Get()
 			*newValue = *E_F_S_T1.Value.(*int64) * *E_F_S_T3.Value.(*int64)
 			E_S_T0.Value = newValue
 		}
@@ -100,7 +111,7 @@ func function(thread int, ruleNum uint16, lhs *symbol, rhs []*symbol) {
 		PLUS2.Next = E_F_S_T3
 
 		{
-			newValue := parserInt64Pools[thread].Get()
+			newValue := ctx.int64Pools[thread].Get()
 			*newValue = *E_S1.Value.(*int64) + *E_F_S_T3.Value.(*int64)
 			E_S0.Value = newValue
 		}
@@ -115,7 +126,7 @@ func function(thread int, ruleNum uint16, lhs *symbol, rhs []*symbol) {
 		PLUS2.Next = E_S_T3
 
 		{
-			newValue := parserInt64Pools[thread].Get()
+			newValue := ctx.int64Pools[thread].Get()
 			*newValue = *E_S1.Value.(*int64) + *E_S_T3.Value.(*int64)
 			E_S0.Value = newValue
 		}
@@ -139,7 +150,7 @@ func function(thread int, ruleNum uint16, lhs *symbol, rhs []*symbol) {
 		PLUS2.Next = E_F_S_T3
 
 		{
-			newValue := parserInt64Pools[thread].Get()
+			newValue := ctx.int64Pools[thread].Get()
 			*newValue = *E_S_T1.Value.(*int64) + *E_F_S_T3.Value.(*int64)
 			E_S0.Value = newValue
 		}
@@ -154,7 +165,7 @@ func function(thread int, ruleNum uint16, lhs *symbol, rhs []*symbol) {
 		PLUS2.Next = E_S_T3
 
 		{
-			newValue := parserInt64Pools[thread].Get()
+			newValue := ctx.int64Pools[thread].Get()
 			*newValue = *E_S_T1.Value.(*int64) + *E_S_T3.Value.(*int64)
 			E_S0.Value = newValue
 		}
@@ -169,7 +180,7 @@ func function(thread int, ruleNum uint16, lhs *symbol, rhs []*symbol) {
 		TIMES2.Next = E_F_S_T3
 
 		{
-			newValue := parserInt64Pools[thread].Get()
+			newValue := ctx.int64Pools[thread].Get()
 			*newValue = *E_S_T1.Value.(*int64) * *E_F_S_T3.Value.(*int64)
 			E_S_T0.Value = newValue
 		}
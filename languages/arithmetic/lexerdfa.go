@@ -1,9 +0,0 @@
-package arithmetic
-
-type lexerDfaState struct {
-	Transitions     [256]int
-	IsFinal         bool
-	AssociatedRules []int
-}
-
-type lexerDfa []lexerDfaState
\ No newline at end of file
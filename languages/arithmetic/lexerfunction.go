@@ -1,3 +1,5 @@
+// Code generated by papageno from arith.l and arith.g. DO NOT EDIT.
+
 package arithmetic
 
 import (
@@ -5,57 +7,67 @@ import (
 	"strconv"
 )
 
-var lexerInt64Pools []*int64Pool
+/*
+lexerContext contains the memory pools of the lexer of a Parser.
+*/
+type lexerContext struct {
+	int64Pools []*int64Pool
+}
 
 /*
 lexerPreallocMem initializes all the memory pools required by the lexer.
 */
-func lexerPreallocMem(inputSize int, numThreads int) {
-	lexerInt64Pools = make([]*int64Pool, numThreads)
-	
+func lexerPreallocMem(ctx *lexerContext, inputSize int, numThreads int) {
+	//The pools of the previous parse are reused if they are large enough
+	for len(ctx.int64Pools) < numThreads {
+		ctx.int64Pools = append(ctx.int64Pools, nil)
+	}
+
 	avgCharsPerNumber := float64(4)
-	
+
 	poolSizePerThread := int(math.Ceil((float64(inputSize) / avgCharsPerNumber) / float64(numThreads)))
 
 	for i := 0; i < numThreads; i++ {
-		lexerInt64Pools[i] = newInt64Pool(poolSizePerThread)
+		ctx.int64Pools[i] = ctx.int64Pools[i].Reuse(poolSizePerThread)
 	}
 }
 
 /*
 lexerFunction is the semantic function of the lexer.
+ctx is the lexerContext of the Parser, initialized by lexerPreallocMem before each parse.
+BEGIN(NAME) in the actions is replaced with the assignment of the start condition NAME to condition.
 */
-func lexerFunction(thread int, ruleNum int, yytext string, genSym *symbol) int {
+func lexerFunction(ctx *lexerContext, thread int, ruleNum int, yytext string, genSym *Symbol, condition *int) int {
 	switch ruleNum {
 	case 0:
 		{
-			*genSym = symbol{LPAR, 0, nil, nil, nil}
+			*genSym = symbol{Token: LPAR}
 			return _LEX_CORRECT
 		}
 	case 1:
 		{
-			*genSym = symbol{RPAR, 0, nil, nil, nil}
+			*genSym = symbol{Token: RPAR}
 			return _LEX_CORRECT
 		}
 	case 2:
 		{
-			*genSym = symbol{TIMES, 0, nil, nil, nil}
+			*genSym = symbol{Token: TIMES}
 			return _LEX_CORRECT
 		}
 	case 3:
 		{
-			*genSym = symbol{PLUS, 0, nil, nil, nil}
+			*genSym = symbol{Token: PLUS}
 			return _LEX_CORRECT
 		}
 	case 4:
 		{
-			num := lexerInt64Pools[thread].Get()
+			num := ctx.int64Pools[thread].Get()
 			err := error(nil)
 			*num, err = strconv.ParseInt(yytext, 10, 64)
 			if err != nil {
 				return _ERROR
 			}
-			*genSym = symbol{NUMBER, 0, num, nil, nil}
+			*genSym = symbol{Token: NUMBER, Value: num}
 			return _LEX_CORRECT
 		}
 	case 5:
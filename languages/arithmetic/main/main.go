@@ -36,6 +36,8 @@ func main() {
 		return
 	}
 
+	parser := arithmetic.NewParser()
+
 	//Code needed for the cpu profiler
 	if *cpuprofile != "" {
 		err := error(nil)
@@ -43,63 +45,65 @@ func main() {
 		if err != nil {
 			log.Fatal("could not create CPU profile: ", err)
 		}
-		arithmetic.SetCPUProfileFile(cpuprofileFile)
+		parser.SetCPUProfileFile(cpuprofileFile)
 	}
 
 	fmt.Println("Available cores:", runtime.GOMAXPROCS(0))
 
 	fmt.Println("Number of threads:", *numThreads)
 
-	root, err := arithmetic.ParseFile(*fname, *numThreads)
+	root, err := parser.ParseFile(*fname, *numThreads)
 
 	if err == nil {
+		stats := parser.Stats()
+
 		fmt.Println("Parse succeded!")
-		for i, v := range arithmetic.Stats.StackPoolSizes {
+		for i, v := range stats.StackPoolSizes {
 			fmt.Printf("Stack pool size (thread %d): %d\n", i, v)
 		}
-		for i, v := range arithmetic.Stats.StackPoolNewNonterminalsSizes {
+		for i, v := range stats.StackPoolNewNonterminalsSizes {
 			fmt.Printf("Stack pool new nonterminals size (thread %d): %d\n", i, v)
 		}
-		for i, v := range arithmetic.Stats.StackPtrPoolSizes {
+		for i, v := range stats.StackPtrPoolSizes {
 			fmt.Printf("StackPtr pool size (thread %d): %d\n", i, v)
 		}
-		fmt.Printf("Stack pool final pass size: %d\n", arithmetic.Stats.StackPoolSizeFinalPass)
-		fmt.Printf("Stack pool final pass new nonterminals size: %d\n", arithmetic.Stats.StackPoolNewNonterminalsSizeFinalPass)
-		fmt.Printf("StackPtr pool final pass size: %d\n", arithmetic.Stats.StackPtrPoolSizeFinalPass)
-		fmt.Printf("Time to alloc memory: %s\n\n", arithmetic.Stats.AllocMemTime)
+		fmt.Printf("Stack pool final pass size: %d\n", stats.StackPoolSizeFinalPass)
+		fmt.Printf("Stack pool final pass new nonterminals size: %d\n", stats.StackPoolNewNonterminalsSizeFinalPass)
+		fmt.Printf("StackPtr pool final pass size: %d\n", stats.StackPtrPoolSizeFinalPass)
+		fmt.Printf("Time to alloc memory: %s\n\n", stats.AllocMemTime)
 
-		for i, v := range arithmetic.Stats.CutPoints {
+		for i, v := range stats.CutPoints {
 			fmt.Printf("cutpoint %d: %d\n", i, v)
 		}
-		for i, v := range arithmetic.Stats.LexTimes {
+		for i, v := range stats.LexTimes {
 			fmt.Printf("Time to lex (thread %d): %s\n", i, v)
 		}
-		fmt.Printf("Time to lex (total): %s\n\n", arithmetic.Stats.LexTimeTotal)
+		fmt.Printf("Time to lex (total): %s\n\n", stats.LexTimeTotal)
 
-		for i, v := range arithmetic.Stats.NumTokens {
+		for i, v := range stats.NumTokens {
 			fmt.Printf("Number of tokens (thread %d): %d\n", i, v)
 		}
-		fmt.Printf("Number of tokens (total): %d\n", arithmetic.Stats.NumTokensTotal)
-		for i, v := range arithmetic.Stats.ParseTimes {
+		fmt.Printf("Number of tokens (total): %d\n", stats.NumTokensTotal)
+		for i, v := range stats.ParseTimes {
 			fmt.Printf("Time to parse (thread %d): %s\n", i, v)
 		}
-		fmt.Printf("Time to recombine the stacks: %s\n", arithmetic.Stats.RecombiningStacksTime)
-		fmt.Printf("Time to parse (final pass): %s\n", arithmetic.Stats.ParseTimeFinalPass)
-		fmt.Printf("Time to parse (total): %s\n\n", arithmetic.Stats.ParseTimeTotal)
+		fmt.Printf("Time to recombine the stacks: %s\n", stats.RecombiningStacksTime)
+		fmt.Printf("Time to parse (final pass): %s\n", stats.ParseTimeFinalPass)
+		fmt.Printf("Time to parse (total): %s\n\n", stats.ParseTimeTotal)
 
-		for i, v := range arithmetic.Stats.RemainingStacks {
+		for i, v := range stats.RemainingStacks {
 			fmt.Printf("Remaining stacks (thread %d): %d\n", i, v)
 		}
-		for i, v := range arithmetic.Stats.RemainingStacksNewNonterminals {
+		for i, v := range stats.RemainingStacksNewNonterminals {
 			fmt.Printf("Remaining stacks new nonterminals (thread %d): %d\n", i, v)
 		}
-		for i, v := range arithmetic.Stats.RemainingStackPtrs {
+		for i, v := range stats.RemainingStackPtrs {
 			fmt.Printf("Remaining stackPtrs (thread %d): %d\n", i, v)
 		}
 
-		fmt.Printf("Remaining stacks final pass: %d\n", arithmetic.Stats.RemainingStacksFinalPass)
-		fmt.Printf("Remaining stacks new nonterminals final pass: %d\n", arithmetic.Stats.RemainingStacksNewNonterminalsFinalPass)
-		fmt.Printf("Remaining stackPtrs final pass: %d\n\n", arithmetic.Stats.RemainingStackPtrsFinalPass)
+		fmt.Printf("Remaining stacks final pass: %d\n", stats.RemainingStacksFinalPass)
+		fmt.Printf("Remaining stacks new nonterminals final pass: %d\n", stats.RemainingStacksNewNonterminalsFinalPass)
+		fmt.Printf("Remaining stackPtrs final pass: %d\n\n", stats.RemainingStackPtrsFinalPass)
 
 		fmt.Printf("Result: %d\n", *root.Value.(*int64))
 	} else {
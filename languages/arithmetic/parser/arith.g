@@ -2,20 +2,28 @@ import (
 	"math"
 )
 
-var parserInt64Pools []*int64Pool
+/*
+parserContext contains the memory pools of the semantic function of a Parser.
+*/
+type parserContext struct {
+	int64Pools []*int64Pool
+}
 
 /*
 parserPreallocMem initializes all the memory pools required by the semantic function of the parser.
 */
-func parserPreallocMem(inputSize int, numThreads int) {
-	parserInt64Pools = make([]*int64Pool, numThreads)
+func parserPreallocMem(ctx *parserContext, inputSize int, numThreads int) {
+	//The pools of the previous parse are reused if they are large enough
+	for len(ctx.int64Pools) < numThreads {
+		ctx.int64Pools = append(ctx.int64Pools, nil)
+	}
 	
 	avgCharsPerNumber := float64(4)
 	
 	poolSizePerThread := int(math.Ceil((float64(inputSize) / avgCharsPerNumber) / float64(numThreads)))
 
 	for i := 0; i < numThreads; i++ {
-		parserInt64Pools[i] = newInt64Pool(poolSizePerThread)
+		ctx.int64Pools[i] = ctx.int64Pools[i].Reuse(poolSizePerThread)
 	}
 }
 %%
@@ -31,7 +39,7 @@ S : E
 
 E : E PLUS T
 {
-	newValue := parserInt64Pools[thread].Get()
+	newValue := ctx.int64Pools[thread].Get()
 	*newValue = *$1.Value.(*int64) + *$3.Value.(*int64)
 	$$.Value = newValue
 } | T
@@ -41,7 +49,7 @@ E : E PLUS T
 
 T : T TIMES F
 {
-	newValue := parserInt64Pools[thread].Get()
+	newValue := ctx.int64Pools[thread].Get()
 	*newValue = *$1.Value.(*int64) * *$3.Value.(*int64)
 	$$.Value = newValue
 } | F
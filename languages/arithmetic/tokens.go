@@ -1,31 +1,50 @@
+// Code generated by papageno from arith.l and arith.g. DO NOT EDIT.
+
 package arithmetic
 
+import "github.com/simoneguidi94/gopapageno/papageno"
+
 const _NUM_NONTERMINALS = 5
 const _NUM_TERMINALS = 6
 
 const (
-	E_F_S_T = iota
-	E_S = iota
-	E_S_T = iota
+	E_F_S_T   = iota
+	E_S       = iota
+	E_S_T     = iota
 	NEW_AXIOM = iota
-	_EMPTY = iota
-	LPAR = 0x8000 + iota - _NUM_NONTERMINALS
-	NUMBER = 0x8000 + iota - _NUM_NONTERMINALS
-	PLUS = 0x8000 + iota - _NUM_NONTERMINALS
-	RPAR = 0x8000 + iota - _NUM_NONTERMINALS
-	TIMES = 0x8000 + iota - _NUM_NONTERMINALS
-	_TERM = 0x8000 + iota - _NUM_NONTERMINALS
+	_EMPTY    = iota
+	LPAR      = 0x8000 + iota - _NUM_NONTERMINALS
+	NUMBER    = 0x8000 + iota - _NUM_NONTERMINALS
+	PLUS      = 0x8000 + iota - _NUM_NONTERMINALS
+	RPAR      = 0x8000 + iota - _NUM_NONTERMINALS
+	TIMES     = 0x8000 + iota - _NUM_NONTERMINALS
+	_TERM     = 0x8000 + iota - _NUM_NONTERMINALS
 )
 
-func tokenValue(token uint16) uint16 {
-	return 0x7FFF & token
-}
+/*
+The exported names of the tokens, to be compared with the Token field of a Symbol.
+*/
+const (
+	TokenE_F_S_T   Token = E_F_S_T
+	TokenE_S       Token = E_S
+	TokenE_S_T     Token = E_S_T
+	TokenNEW_AXIOM Token = NEW_AXIOM
+	TokenLPAR      Token = LPAR
+	TokenNUMBER    Token = NUMBER
+	TokenPLUS      Token = PLUS
+	TokenRPAR      Token = RPAR
+	TokenTIMES     Token = TIMES
+)
 
-func isTerminal(token uint16) bool {
-	return token >= 0x800
+/*
+TokenString returns the name of a token as written in the specifications,
+or _ERROR for the error nodes that replace the symbols that could not be parsed.
+*/
+func TokenString(token Token) string {
+	return tokenToString(token)
 }
 
-func tokenToString(token uint16) string {
+func tokenToString(token Token) string {
 	switch token {
 	case E_F_S_T:
 		return "E_F_S_T"
@@ -49,6 +68,8 @@ func tokenToString(token uint16) string {
 		return "TIMES"
 	case _TERM:
 		return "_TERM"
+	case papageno.ErrorToken:
+		return "_ERROR"
 	}
 	return "UNKNOWN_TOKEN"
-}
\ No newline at end of file
+}
@@ -71,6 +71,10 @@ func main() {
 	defer parseParallelCodeFile.Close()
 	parseParallelCodeFile.Write([]byte("1, 0\n"))
 
+	//The garbage collector is run before each parse, so that it does not affect the measured times
+	parser := xml.NewParser()
+	parser.SetForceGC(true)
+
 	for i := 0; i < *numThreads; i++ {
 		curNumThreads := i + 1
 
@@ -86,19 +90,19 @@ func main() {
 		for j := 0; j < *numTests; j++ {
 			fmt.Printf("Test n° %d:\n", j+1)
 
-			_, err := xml.ParseFile(*fname, curNumThreads)
+			_, err := parser.ParseFile(*fname, curNumThreads)
 
 			runtime.GC()
 
 			if err == nil {
 				fmt.Println("Parse succeded!")
-				fmt.Printf("Time to alloc memory: %s\n", xml.Stats.AllocMemTime)
-				fmt.Printf("Time to lex: %s\n", xml.Stats.LexTimeTotal)
-				fmt.Printf("Time to parse: %s\n\n", xml.Stats.ParseTimeTotal)
+				fmt.Printf("Time to alloc memory: %s\n", parser.Stats().AllocMemTime)
+				fmt.Printf("Time to lex: %s\n", parser.Stats().LexTimeTotal)
+				fmt.Printf("Time to parse: %s\n\n", parser.Stats().ParseTimeTotal)
 
-				memAllocTimes[j] = xml.Stats.AllocMemTime
-				lexTimes[j] = xml.Stats.LexTimeTotal
-				parseTimes[j] = xml.Stats.ParseTimeTotal
+				memAllocTimes[j] = parser.Stats().AllocMemTime
+				lexTimes[j] = parser.Stats().LexTimeTotal
+				parseTimes[j] = parser.Stats().ParseTimeTotal
 			} else {
 				//This should not happen
 				fmt.Println("Parse failed!")
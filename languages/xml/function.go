@@ -1,15 +1,24 @@
+// Code generated by papageno from xml.l and xml.g. DO NOT EDIT.
+
 package xml
 
 /*
 parserPreallocMem initializes all the memory pools required by the semantic function of the parser.
 */
-func parserPreallocMem(inputSize int, numThreads int) {
+func parserPreallocMem(ctx *parserContext, inputSize int, numThreads int) {
 }
 
+/*
+parserContext contains the state of the semantic function of the parser owned by each Parser,
+which the specification of the grammar did not declare.
+*/
+type parserContext struct{}
+
 /*
 function is the semantic function of the parser.
+ctx is the parserContext of the Parser, initialized by parserPreallocMem before each parse.
 */
-func function(thread int, ruleNum uint16, lhs *symbol, rhs []*symbol) {
+func function(ctx *parserContext, thread int, ruleNum uint16, lhs *Symbol, rhs []*Symbol) {
 	switch ruleNum {
 	case 0:
 		NEW_AXIOM0 := lhs
@@ -1,49 +1,59 @@
+// Code generated by papageno from xml.l and xml.g. DO NOT EDIT.
+
 package xml
 
 /*
 lexerPreallocMem initializes all the memory pools required by the lexer.
 */
-func lexerPreallocMem(inputSize int, numThreads int) {
+func lexerPreallocMem(ctx *lexerContext, inputSize int, numThreads int) {
 }
 
+/*
+lexerContext contains the state of the semantic function of the lexer owned by each Parser,
+which the specification of the lexer did not declare.
+*/
+type lexerContext struct{}
+
 /*
 lexerFunction is the semantic function of the lexer.
+ctx is the lexerContext of the Parser, initialized by lexerPreallocMem before each parse.
+BEGIN(NAME) in the actions is replaced with the assignment of the start condition NAME to condition.
 */
-func lexerFunction(thread int, ruleNum int, yytext string, genSym *symbol) int {
+func lexerFunction(ctx *lexerContext, thread int, ruleNum int, yytext string, genSym *Symbol, condition *int) int {
 	switch ruleNum {
 	case 0:
 		{
-			*genSym = symbol{infos, 0, nil, nil, nil}
+			*genSym = symbol{Token: infos}
 			return _LEX_CORRECT
 		}
 	case 1:
 		{
-			*genSym = symbol{openbracket, 0, nil, nil, nil}
+			*genSym = symbol{Token: openbracket}
 			return _LEX_CORRECT
 		}
 	case 2:
 		{
-			*genSym = symbol{closebracket, 0, nil, nil, nil}
+			*genSym = symbol{Token: closebracket}
 			return _LEX_CORRECT
 		}
 	case 3:
 		{
-			*genSym = symbol{alternativeclose, 0, nil, nil, nil}
+			*genSym = symbol{Token: alternativeclose}
 			return _LEX_CORRECT
 		}
 	case 4:
 		{
-			*genSym = symbol{openparams, 0, nil, nil, nil}
+			*genSym = symbol{Token: openparams}
 			return _LEX_CORRECT
 		}
 	case 5:
 		{
-			*genSym = symbol{opencloseinfo, 0, nil, nil, nil}
+			*genSym = symbol{Token: opencloseinfo}
 			return _LEX_CORRECT
 		}
 	case 6:
 		{
-			*genSym = symbol{opencloseparam, 0, nil, nil, nil}
+			*genSym = symbol{Token: opencloseparam}
 			return _LEX_CORRECT
 		}
 	case 7:
@@ -59,6 +69,24 @@ func lexerFunction(thread int, ruleNum int, yytext string, genSym *symbol) int {
 			return _SKIP
 		}
 	case 10:
+		{
+			*condition = 1 /* COMMENT */
+			return _SKIP
+		}
+	case 11:
+		{
+			*condition = 0 /* INITIAL */
+			return _SKIP
+		}
+	case 12:
+		{
+			return _SKIP
+		}
+	case 13:
+		{
+			return _SKIP
+		}
+	case 14:
 		{
 			return _ERROR
 		}
@@ -1,7 +1,7 @@
 /*
 parserPreallocMem initializes all the memory pools required by the semantic function of the parser.
 */
-func parserPreallocMem(inputSize int, numThreads int) {
+func parserPreallocMem(ctx *parserContext, inputSize int, numThreads int) {
 }
 %%
 
@@ -1,32 +1,52 @@
+// Code generated by papageno from xml.l and xml.g. DO NOT EDIT.
+
 package xml
 
+import "github.com/simoneguidi94/gopapageno/papageno"
+
 const _NUM_NONTERMINALS = 3
 const _NUM_TERMINALS = 9
 
 const (
-	ELEM = iota
-	NEW_AXIOM = iota
-	_EMPTY = iota
-	_TERM = 0x8000 + iota - _NUM_NONTERMINALS
+	ELEM             = iota
+	NEW_AXIOM        = iota
+	_EMPTY           = iota
+	_TERM            = 0x8000 + iota - _NUM_NONTERMINALS
 	alternativeclose = 0x8000 + iota - _NUM_NONTERMINALS
-	closebracket = 0x8000 + iota - _NUM_NONTERMINALS
-	closeparams = 0x8000 + iota - _NUM_NONTERMINALS
-	infos = 0x8000 + iota - _NUM_NONTERMINALS
-	openbracket = 0x8000 + iota - _NUM_NONTERMINALS
-	opencloseinfo = 0x8000 + iota - _NUM_NONTERMINALS
-	opencloseparam = 0x8000 + iota - _NUM_NONTERMINALS
-	openparams = 0x8000 + iota - _NUM_NONTERMINALS
+	closebracket     = 0x8000 + iota - _NUM_NONTERMINALS
+	closeparams      = 0x8000 + iota - _NUM_NONTERMINALS
+	infos            = 0x8000 + iota - _NUM_NONTERMINALS
+	openbracket      = 0x8000 + iota - _NUM_NONTERMINALS
+	opencloseinfo    = 0x8000 + iota - _NUM_NONTERMINALS
+	opencloseparam   = 0x8000 + iota - _NUM_NONTERMINALS
+	openparams       = 0x8000 + iota - _NUM_NONTERMINALS
 )
 
-func tokenValue(token uint16) uint16 {
-	return 0x7FFF & token
-}
+/*
+The exported names of the tokens, to be compared with the Token field of a Symbol.
+*/
+const (
+	TokenELEM             Token = ELEM
+	TokenNEW_AXIOM        Token = NEW_AXIOM
+	TokenAlternativeclose Token = alternativeclose
+	TokenClosebracket     Token = closebracket
+	TokenCloseparams      Token = closeparams
+	TokenInfos            Token = infos
+	TokenOpenbracket      Token = openbracket
+	TokenOpencloseinfo    Token = opencloseinfo
+	TokenOpencloseparam   Token = opencloseparam
+	TokenOpenparams       Token = openparams
+)
 
-func isTerminal(token uint16) bool {
-	return token >= 0x800
+/*
+TokenString returns the name of a token as written in the specifications,
+or _ERROR for the error nodes that replace the symbols that could not be parsed.
+*/
+func TokenString(token Token) string {
+	return tokenToString(token)
 }
 
-func tokenToString(token uint16) string {
+func tokenToString(token Token) string {
 	switch token {
 	case ELEM:
 		return "ELEM"
@@ -52,6 +72,8 @@ func tokenToString(token uint16) string {
 		return "opencloseparam"
 	case openparams:
 		return "openparams"
+	case papageno.ErrorToken:
+		return "_ERROR"
 	}
 	return "UNKNOWN_TOKEN"
-}
\ No newline at end of file
+}
@@ -0,0 +1,125 @@
+package xml
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+/*
+TestCommentAcrossCutPoints parses a document with a long comment using more threads,
+so that some cut points fall inside the comment, which is lexed in the COMMENT start condition.
+*/
+func TestCommentAcrossCutPoints(t *testing.T) {
+	data := []byte("<a>\n<!-- " + strings.Repeat("<b>x</b>\n", 200) + " -->\n<c>y</c>\n</a>\n")
+
+	parser := NewParser()
+
+	for _, numThreads := range []int{1, 2, 4} {
+		root, err := parser.ParseString(data, numThreads)
+
+		if err != nil {
+			t.Fatalf("Error with %d threads: %s", numThreads, err.Error())
+		}
+		if root.Offset != 0 || root.Length != len(data)-1 {
+			t.Errorf("Error: the root spans %d bytes from %d with %d threads, should span %d bytes from 0", root.Length, root.Offset, numThreads, len(data)-1)
+		}
+		if numThreads > 1 && parser.Stats().NumLexThreads < 2 {
+			t.Errorf("Error: the input was not split with %d threads", numThreads)
+		}
+	}
+}
+
+/*
+BenchmarkParse parses the 1MB corpus with one and four threads,
+reporting the time spent lexing besides the total time of each parse.
+*/
+func BenchmarkParse(b *testing.B) {
+	data, err := os.ReadFile("data/1MB.xml")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	parser := NewParser()
+
+	for _, numThreads := range []int{1, 4} {
+		name := "1thread"
+		if numThreads > 1 {
+			name = "4threads"
+		}
+		b.Run(name, func(b *testing.B) {
+			b.SetBytes(int64(len(data)))
+			var lexTime int64
+			for i := 0; i < b.N; i++ {
+				if _, err := parser.ParseString(data, numThreads); err != nil {
+					b.Fatal(err)
+				}
+				lexTime += parser.Stats().LexTimeTotal.Nanoseconds()
+			}
+			b.ReportMetric(float64(lexTime)/float64(b.N), "lex-ns/op")
+		})
+	}
+}
+
+/*
+smallDocuments splits the rows of the 1MB corpus into documents of about size bytes,
+each with its own root element.
+*/
+func smallDocuments(b *testing.B, size int) [][]byte {
+	data, err := os.ReadFile("data/1MB.xml")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	documents := make([][]byte, 0)
+	var document bytes.Buffer
+
+	for _, row := range bytes.Split(data, []byte("\n")) {
+		if !bytes.HasPrefix(row, []byte("<T>")) {
+			continue
+		}
+		if document.Len() == 0 {
+			document.WriteString("<table ID=\"partsupp\">\n")
+		}
+		document.Write(row)
+		document.WriteString("\n")
+		if document.Len() >= size {
+			document.WriteString("</table>\n")
+			documents = append(documents, append([]byte(nil), document.Bytes()...))
+			document.Reset()
+		}
+	}
+
+	return documents
+}
+
+/*
+BenchmarkParseSmallDocuments parses many documents of 4KB, either creating a Parser
+and running the garbage collector for each of them, as the parsers used to do,
+or reusing the memory pools of a single Parser.
+*/
+func BenchmarkParseSmallDocuments(b *testing.B) {
+	documents := smallDocuments(b, 4096)
+
+	b.Run("new", func(b *testing.B) {
+		b.SetBytes(int64(len(documents[0])))
+		for i := 0; i < b.N; i++ {
+			parser := NewParser()
+			parser.SetForceGC(true)
+			if _, err := parser.ParseString(documents[i%len(documents)], 1); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("reused", func(b *testing.B) {
+		b.SetBytes(int64(len(documents[0])))
+		parser := NewParser()
+		for i := 0; i < b.N; i++ {
+			if _, err := parser.ParseString(documents[i%len(documents)], 1); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,184 @@
+package papageno
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+/*
+Phase identifies the phase of a parse in which a ParseError occurred.
+*/
+type Phase int
+
+/*
+The phases of a parse: the parallel lexing, the parallel parsing of the parts of the input
+and the final pass that combines the results of the parsing threads.
+*/
+const (
+	PhaseLexing Phase = iota
+	PhaseParsing
+	PhaseFinalPass
+)
+
+func (phase Phase) String() string {
+	switch phase {
+	case PhaseLexing:
+		return "lexing"
+	case PhaseParsing:
+		return "parsing"
+	case PhaseFinalPass:
+		return "final pass"
+	}
+	return "unknown phase"
+}
+
+/*
+ParseError is the error returned when an input cannot be lexed or parsed.
+Offset is the position in bytes of the text that could not be lexed, or of the offending token,
+while Line and Column are the same position starting from 1, which are 0 when the input was not lexed by papageno.
+Token is the name of the offending token and StackTop the name of the terminal on top of the stack,
+which is _TERM at the beginning of the input; both are empty for a lexing error.
+If there is no precedence relation between them, Expected contains the terminals that would have had one.
+Otherwise Handle contains the symbols that could not be reduced, since no rule has them as rhs.
+*/
+type ParseError struct {
+	Phase    Phase
+	Offset   int
+	Line     int
+	Column   int
+	Token    string
+	StackTop string
+	Expected []string
+	Handle   []string
+}
+
+func (e *ParseError) Error() string {
+	var sb strings.Builder
+
+	if e.Line > 0 {
+		fmt.Fprintf(&sb, "%d:%d: ", e.Line, e.Column)
+	} else {
+		fmt.Fprintf(&sb, "offset %d: ", e.Offset)
+	}
+
+	fmt.Fprintf(&sb, "%s error: ", e.Phase)
+
+	switch {
+	case e.Phase == PhaseLexing:
+		sb.WriteString("no lexer rule matches the input")
+	case e.Handle != nil:
+		fmt.Fprintf(&sb, "no rule reduces %s before %s", strings.Join(e.Handle, " "), e.Token)
+	default:
+		fmt.Fprintf(&sb, "unexpected %s after %s", e.Token, e.StackTop)
+		if len(e.Expected) > 0 {
+			fmt.Fprintf(&sb, ", expected one of %s", strings.Join(e.Expected, ", "))
+		}
+	}
+
+	return sb.String()
+}
+
+/*
+tokenName returns the name of a token, or its number if the grammar has no TokenString function.
+*/
+func (g *Grammar) tokenName(token Token) string {
+	if g.TokenString != nil {
+		return g.TokenString(token)
+	}
+	return fmt.Sprintf("%#x", uint16(token))
+}
+
+/*
+noPrecError returns the error of a parsing thread that found no precedence relation
+between the terminal on top of the stack and the input symbol.
+*/
+func (g *Grammar) noPrecError(phase Phase, stackTop *Symbol, inputSym *Symbol) *ParseError {
+	expected := make([]string, 0)
+	for i := 0; i < g.NumTerminals; i++ {
+		token := Token(0x8000 | i)
+		if g.getPrecedence(stackTop.Token, token) != _NO_PREC {
+			expected = append(expected, g.tokenName(token))
+		}
+	}
+
+	return &ParseError{
+		Phase:    phase,
+		Offset:   inputSym.Offset,
+		Token:    g.tokenName(inputSym.Token),
+		StackTop: g.tokenName(stackTop.Token),
+		Expected: expected,
+	}
+}
+
+/*
+noMatchError returns the error of a parsing thread that found no rule to reduce the symbols of rhs.
+*/
+func (g *Grammar) noMatchError(phase Phase, stackTop *Symbol, inputSym *Symbol, rhs []Token) *ParseError {
+	handle := make([]string, len(rhs))
+	for i, token := range rhs {
+		handle[i] = g.tokenName(token)
+	}
+
+	return &ParseError{
+		Phase:    phase,
+		Offset:   inputSym.Offset,
+		Token:    g.tokenName(inputSym.Token),
+		StackTop: g.tokenName(stackTop.Token),
+		Handle:   handle,
+	}
+}
+
+/*
+ParseErrors is returned when the parser recovers from the syntax errors, which happens if the grammar
+declares synchronizing terminals. It contains every error found, sorted by offset,
+and its Error method returns one error per line.
+*/
+type ParseErrors []*ParseError
+
+func (l ParseErrors) Error() string {
+	msgs := make([]string, len(l))
+	for i, e := range l {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+/*
+Unwrap returns the errors of the list, so that errors.As finds the first one.
+*/
+func (l ParseErrors) Unwrap() []error {
+	errs := make([]error, len(l))
+	for i, e := range l {
+		errs[i] = e
+	}
+	return errs
+}
+
+/*
+newParseError returns the error of a parse: nil if errs is empty, its first error if the parser
+does not recover from the errors or ParseErrors otherwise.
+If str is not nil the errors are located in it, setting their lines and columns.
+*/
+func newParseError(errs []*ParseError, recovering bool, str []byte) error {
+	if len(errs) == 0 {
+		return nil
+	}
+
+	if str != nil {
+		index := NewLineIndex(str)
+		for _, e := range errs {
+			e.Line, e.Column = index.Position(e.Offset)
+		}
+	}
+
+	if !recovering {
+		return errs[0]
+	}
+
+	sort.SliceStable(errs, func(i, j int) bool {
+		return errs[i].Offset < errs[j].Offset
+	})
+
+	return ParseErrors(errs)
+}
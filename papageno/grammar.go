@@ -0,0 +1,90 @@
+package papageno
+
+/*
+SemanticFunction is the semantic function of a parser. It is called for each reduction
+with the number of the rule, the new nonterminal and the symbols of the rhs.
+*/
+type SemanticFunction func(thread int, ruleNum uint16, lhs *Symbol, rhs []*Symbol)
+
+/*
+Grammar contains the tables and the semantic function of a generated parser.
+Empty is the token returned by the compressed trie when no rule matches,
+while Term is the terminal that delimits the input.
+PrecMatrix is the bitpacked precedence matrix, where each precedence relation takes 2 bits.
+PreallocMem, if not nil, is called before each parse to initialize the memory pools of Function.
+TokenString, if not nil, returns the name of a token, which is used in the errors of a parse.
+SyncTerminals contains the synchronizing terminals: if there is at least one, the parser recovers
+from the syntax errors instead of stopping at the first one.
+*/
+type Grammar struct {
+	NumTerminals   int
+	MaxRHSLen      int
+	Empty          Token
+	Term           Token
+	CompressedTrie []uint16
+	PrecMatrix     []uint64
+	Function       SemanticFunction
+	PreallocMem    func(inputSize int, numThreads int)
+	TokenString    func(token Token) string
+	SyncTerminals  []Token
+}
+
+/*
+findMatch tries to find a match for the rhs using the compressed trie.
+On success it returns the corresponding lhs and the rule number.
+On failure it returns Empty as lhs.
+*/
+func (g *Grammar) findMatch(rhs []Token) (Token, uint16) {
+	compressedTrie := g.CompressedTrie
+	pos := uint16(0)
+
+	for _, key := range rhs {
+		//Skip the value and rule num for each node (except the last)
+		pos += 2
+		numIndices := compressedTrie[pos]
+		if numIndices == 0 {
+			return g.Empty, 0
+		}
+		pos++
+		//low and high are signed, so that high may become -1 when the key is smaller than every index
+		low := 0
+		high := int(numIndices) - 1
+		startPos := pos
+		foundNext := false
+
+		for low <= high {
+			indexpos := low + (high-low)/2
+			pos = startPos + uint16(indexpos*2)
+			curKey := Token(compressedTrie[pos])
+
+			if key < curKey {
+				high = indexpos - 1
+			} else if key > curKey {
+				low = indexpos + 1
+			} else {
+				pos = compressedTrie[pos+1]
+				foundNext = true
+				break
+			}
+		}
+		if !foundNext {
+			return g.Empty, 0
+		}
+	}
+
+	return Token(compressedTrie[pos]), compressedTrie[pos+1]
+}
+
+/*
+getPrecedence returns the precedence between two tokens using the bitpacked precedence matrix.
+*/
+func (g *Grammar) getPrecedence(token1 Token, token2 Token) uint16 {
+	tv1 := int(tokenValue(token1))
+	tv2 := int(tokenValue(token2))
+
+	flatElemPos := tv1*g.NumTerminals + tv2
+	elem := g.PrecMatrix[flatElemPos/32]
+	pos := uint((flatElemPos % 32) * 2)
+
+	return uint16((elem >> pos) & 0x3)
+}
@@ -0,0 +1,56 @@
+package papageno
+
+/*
+Int64Pool allows to preallocate elements with type int64.
+It is not thread-safe, you have to create one for each thread.
+*/
+type Int64Pool struct {
+	pool []int64
+	cur  int
+}
+
+/*
+NewInt64Pool creates a new pool, allocating memory for a number of elements equal to length.
+*/
+func NewInt64Pool(length int) *Int64Pool {
+	p := Int64Pool{make([]int64, length), 0}
+
+	for i := 0; i < length; i++ {
+		p.pool[i] = int64(0)
+	}
+
+	return &p
+}
+
+/*
+Get gets an item from the pool if available, otherwise it initializes a new one.
+It is NOT thread-safe.
+*/
+func (p *Int64Pool) Get() *int64 {
+	if p.cur >= len(p.pool) {
+		return new(int64)
+	}
+	addr := &p.pool[p.cur]
+	p.cur++
+	return addr
+}
+
+/*
+Remainder returns the number of items remaining in the pool.
+*/
+func (p *Int64Pool) Remainder() int {
+	return len(p.pool) - p.cur
+}
+
+/*
+Reuse returns the pool, making all its items available again, if it contains at least length items,
+otherwise it returns a new pool with length items. It may be called on a nil pool,
+so that the memory pools of the semantic functions can be kept between the parses.
+*/
+func (p *Int64Pool) Reuse(length int) *Int64Pool {
+	if p == nil || len(p.pool) < length {
+		return NewInt64Pool(length)
+	}
+	p.cur = 0
+	return p
+}
@@ -0,0 +1,230 @@
+package papageno
+
+import (
+	"math"
+	"time"
+	"unsafe"
+)
+
+/*
+The results of a lexer semantic function and of yyLex.
+*/
+const (
+	LexError     = -1
+	LexEndOfFile = 0
+	LexCorrect   = 1
+	LexSkip      = 2
+)
+
+/*
+LexerNoTransition marks a missing transition in the table of a LexerDfa.
+*/
+const LexerNoTransition = math.MaxUint16
+
+/*
+LexerDfa is a deterministic automaton whose first state is the initial one.
+The bytes are grouped in classes of bytes that lead each state to the same state:
+Classes contains the class of each byte and Transitions contains, for each state, a row of NumClasses
+entries with the index of the next state for each class, or LexerNoTransition if there is no transition.
+IsFinal and AssociatedRules are indexed by state, and AssociatedRules contains the sorted lexer rules
+that match when the automaton stops in the state.
+*/
+type LexerDfa struct {
+	Classes         [256]uint8
+	NumClasses      int
+	Transitions     []uint16
+	IsFinal         []bool
+	AssociatedRules [][]int
+}
+
+/*
+Next returns the state reached from state reading c, or -1 if there is no transition.
+*/
+func (dfa *LexerDfa) Next(state int, c byte) int {
+	next := dfa.Transitions[state*dfa.NumClasses+int(dfa.Classes[c])]
+	if next == LexerNoTransition {
+		return -1
+	}
+	return int(next)
+}
+
+/*
+LexerFunction is the semantic function of a lexer. It is called with the number of the rule
+that matched the text yytext and must set genSym accordingly.
+condition points to the active start condition, which the function may change.
+It returns LexCorrect if a symbol was produced, LexSkip if the text must be ignored or LexError.
+*/
+type LexerFunction func(thread int, ruleNum int, yytext string, genSym *Symbol, condition *int) int
+
+/*
+Lexer contains the tables and the semantic function of a generated lexer.
+Automata contains an automaton recognizing the tokens for each start condition,
+the first being the one active when the lexing begins,
+while CutPointsAutomaton recognizes the points where the input may be split between the lexing threads.
+Each thread starts lexing in the first start condition, and if the previous thread ends in a different one
+the part of the input of the thread is lexed again, sequentially, starting from that condition.
+PreallocMem, if not nil, is called before each parse to initialize the memory pools of Function.
+*/
+type Lexer struct {
+	Automata           []LexerDfa
+	CutPointsAutomaton LexerDfa
+	Function           LexerFunction
+	PreallocMem        func(inputSize int, numThreads int)
+}
+
+/*
+lexer contains the file data, the current position and the active start condition.
+offset is the position of data in the whole input, which is added to the offsets of the symbols.
+*/
+type lexer struct {
+	*Lexer
+	data      []byte
+	offset    int
+	pos       int
+	condition int
+}
+
+/*
+yyLex reads a token from the lexer and creates a new symbol, saving it in genSym.
+It returns one of the following codes:
+LexError     if a token couldn't be read, leaving the position at the beginning of its text
+LexEndOfFile if there's no more data to lex
+LexCorrect   if a token was successfully read
+*/
+func (l *lexer) yyLex(thread int, genSym *Symbol) int {
+	data := l.data
+	result := LexSkip
+	for result == LexSkip {
+		lastFinalStateReached := -1
+		var lastFinalStatePos int
+		startPos := l.pos
+		automaton := &l.Automata[l.condition]
+		//The same lookup as automaton.Next, written in the loop to keep it fast
+		classes := &automaton.Classes
+		transitions := automaton.Transitions
+		numClasses := automaton.NumClasses
+		isFinal := automaton.IsFinal
+		curState := 0
+
+		//Read chars until there are no more transitions or no more data, remembering the last final state
+		for pos := startPos; pos < len(data); pos++ {
+			nextState := int(transitions[curState*numClasses+int(classes[data[pos]])])
+
+			if nextState == LexerNoTransition {
+				break
+			}
+
+			curState = nextState
+			if isFinal[curState] {
+				lastFinalStateReached = curState
+				lastFinalStatePos = pos
+			}
+		}
+
+		//If no final state was reached the text cannot be lexed, unless there is no text left
+		if lastFinalStateReached == -1 {
+			if startPos == len(data) {
+				return LexEndOfFile
+			}
+			return LexError
+		}
+
+		//The token ends at the last final state, the following chars are lexed again by the next call
+		l.pos = lastFinalStatePos + 1
+		ruleNum := automaton.AssociatedRules[lastFinalStateReached][0]
+		textBytes := data[startPos:l.pos]
+		//TODO should be changed to safe code when Go supports no-op []byte to string conversion
+		text := *(*string)(unsafe.Pointer(&textBytes))
+		//fmt.Printf("%s: %d\n", text, ruleNum)
+		result = l.Function(thread, ruleNum, text, genSym, &l.condition)
+		if result == LexError {
+			l.pos = startPos
+		}
+		genSym.Offset = l.offset + startPos
+		genSym.Length = l.pos - startPos
+	}
+	return result
+}
+
+/*
+findCutPoints cuts the input in specific points determined by a regular expression defined at generation time.
+*/
+func findCutPoints(cutPointsAutomaton *LexerDfa, data []byte, numThreads int) ([]int, int) {
+	dataSize := len(data)
+	avgBytesPerThread := dataSize / numThreads
+	cutPoints := make([]int, numThreads+1)
+	cutPoints[0] = 0
+	cutPoints[len(cutPoints)-1] = dataSize
+
+	for i := 1; i < numThreads; i++ {
+		startPos := cutPoints[i-1] + avgBytesPerThread
+		curPos := startPos
+		curState := 0
+		for !cutPointsAutomaton.IsFinal[curState] {
+			if curPos >= dataSize {
+				return append(cutPoints[0:i], cutPoints[len(cutPoints)-1]), i
+			}
+			nextState := cutPointsAutomaton.Next(curState, data[curPos])
+			//No more transitions are possible, reset the automaton state
+			if nextState == -1 {
+				startPos = curPos + 1
+				curState = 0
+			} else {
+				curState = nextState
+			}
+			curPos++
+		}
+		cutPoints[i] = startPos
+	}
+
+	return cutPoints, numThreads
+}
+
+/*
+lex is the lexing function executed in parallel by each thread.
+It takes as input the data to lex, its offset in the whole input, the start condition where the lexing begins
+and a channel where it eventually sends the result in form of a listOfStacks containing the lexed symbols,
+together with the lexing errors and the start condition active at the end.
+If recovering is false the lexing stops at the first error, otherwise the bytes that cannot be lexed are skipped
+and only the first of consecutive ones is reported.
+*/
+func lex(l *Lexer, stats *ParsingStats, threadNum int, data []byte, offset int, condition int, recovering bool, pool *stackPool, c chan lexResult) {
+	start := time.Now()
+
+	los := newLos(pool)
+
+	errs := make([]*ParseError, 0)
+	errorEnd := -1
+
+	sym := Symbol{}
+
+	lexer := lexer{l, data, offset, 0, condition}
+
+	//Lex the first symbol
+	res := lexer.yyLex(threadNum, &sym)
+
+	//Keep lexing until the end of the file is reached or an error occurs
+	for res != LexEndOfFile {
+		if res == LexError {
+			if lexer.pos != errorEnd {
+				errs = append(errs, &ParseError{Phase: PhaseLexing, Offset: offset + lexer.pos})
+			}
+
+			if !recovering {
+				c <- lexResult{threadNum, &los, errs, lexer.condition}
+				return
+			}
+
+			lexer.pos++
+			errorEnd = lexer.pos
+		} else {
+			los.Push(&sym)
+		}
+
+		res = lexer.yyLex(threadNum, &sym)
+	}
+
+	stats.LexTimes[threadNum] = time.Since(start)
+
+	c <- lexResult{threadNum, &los, errs, lexer.condition}
+}
@@ -1,4 +1,4 @@
-package xml
+package papageno
 
 import (
 	"fmt"
@@ -37,7 +37,7 @@ func newLos(pool *stackPool) listOfStacks {
 Push pushes a symbol in the listOfStacks.
 It returns a pointer to the pushed symbol.
 */
-func (l *listOfStacks) Push(sym *symbol) *symbol {
+func (l *listOfStacks) Push(sym *Symbol) *Symbol {
 	curStack := l.cur
 
 	//If the current stack is full obtain a new stack and set it as the current one
@@ -87,7 +87,7 @@ func (l *listOfStacks) Push(sym *symbol) *symbol {
 /*
 Pop pops a symbol from the stack and returns a pointer to it.
 */
-func (l *listOfStacks) Pop() *symbol {
+func (l *listOfStacks) Pop() *Symbol {
 	curStack := l.cur
 
 	//Decrement the current position
@@ -122,8 +122,19 @@ func (l *listOfStacks) Pop() *symbol {
 
 /*
 Merge merges a listOfStacks to another by linking their stacks.
+Empty lists are not linked, so that the lists obtained by Split are never empty.
 */
 func (l *listOfStacks) Merge(l2 listOfStacks) {
+	if l2.len == 0 {
+		return
+	}
+	if l.len == 0 {
+		l.head = l2.head
+		l.cur = l2.cur
+		l.len = l2.len
+		return
+	}
+
 	l.cur.Next = l2.head
 	l2.head.Prev = l.cur
 	l.cur = l2.cur
@@ -207,7 +218,7 @@ func (l *listOfStacks) Println() {
 
 	sym := iterator.Next()
 	for sym != nil {
-		fmt.Printf("(%s, %s) -> ", tokenToString(sym.Token), precToString(sym.Precedence))
+		fmt.Printf("(%d, %s) -> ", sym.Token, precToString(sym.Precedence))
 		sym = iterator.Next()
 	}
 	fmt.Println()
@@ -231,7 +242,7 @@ func (l *listOfStacks) TailIterator() iterator {
 Prev moves the iterator one position backward and returns a pointer to the current symbol.
 It returns nil if it points before the first element of the list.
 */
-func (i *iterator) Prev() *symbol {
+func (i *iterator) Prev() *Symbol {
 	curStack := i.cur
 
 	i.pos--
@@ -255,7 +266,7 @@ func (i *iterator) Prev() *symbol {
 Cur returns a pointer to the current symbol.
 It returns nil if it points before the first element or after the last element of the list.
 */
-func (i *iterator) Cur() *symbol {
+func (i *iterator) Cur() *Symbol {
 	curStack := i.cur
 
 	if i.pos >= 0 && i.pos < curStack.Tos {
@@ -269,7 +280,7 @@ func (i *iterator) Cur() *symbol {
 Next moves the iterator one position forward and returns a pointer to the current symbol.
 It returns nil if it points after the last element of the list.
 */
-func (i *iterator) Next() *symbol {
+func (i *iterator) Next() *Symbol {
 	curStack := i.cur
 
 	i.pos++
@@ -1,3 +1,5 @@
+package papageno
+
 import (
 	"fmt"
 	"math"
@@ -11,7 +13,7 @@ type listOfStackPtrs struct {
 	head          *stackPtr
 	cur           *stackPtr
 	len           int
-	firstTerminal *symbol
+	firstTerminal *Symbol
 	pool          *stackPtrPool
 }
 
@@ -36,7 +38,7 @@ func newLosPtr(pool *stackPtrPool) listOfStackPtrs {
 Push pushes a symbol pointer in the listOfStackPtrs.
 It returns the pointer itself.
 */
-func (l *listOfStackPtrs) Push(sym *symbol) *symbol {
+func (l *listOfStackPtrs) Push(sym *Symbol) *Symbol {
 	curStack := l.cur
 
 	//If the current stack is full obtain a new stack and set it as the current one
@@ -90,7 +92,7 @@ func (l *listOfStackPtrs) Push(sym *symbol) *symbol {
 /*
 Pop pops a symbol pointer from the stack and returns it.
 */
-func (l *listOfStackPtrs) Pop() *symbol {
+func (l *listOfStackPtrs) Pop() *Symbol {
 	curStack := l.cur
 
 	//Decrement the current position
@@ -208,7 +210,7 @@ func (l *listOfStackPtrs) NumStacks() int {
 /*
 FirstTerminal returns a pointer to the first terminal on the stack.
 */
-func (l *listOfStackPtrs) FirstTerminal() *symbol {
+func (l *listOfStackPtrs) FirstTerminal() *Symbol {
 	return l.firstTerminal
 }
 
@@ -224,7 +226,7 @@ func (l *listOfStackPtrs) UpdateFirstTerminal() {
 /*
 This function is for internal usage only.
 */
-func (l *listOfStackPtrs) findFirstTerminal() *symbol {
+func (l *listOfStackPtrs) findFirstTerminal() *Symbol {
 	curStack := l.cur
 
 	pos := curStack.Tos - 1
@@ -261,7 +263,7 @@ func (l *listOfStackPtrs) Println() {
 
 	sym := iterator.Next()
 	for sym != nil {
-		fmt.Printf("(%s, %s) -> ", tokenToString(sym.Token), precToString(sym.Precedence))
+		fmt.Printf("(%d, %s) -> ", sym.Token, precToString(sym.Precedence))
 		sym = iterator.Next()
 	}
 	fmt.Println()
@@ -285,7 +287,7 @@ func (l *listOfStackPtrs) TailIterator() iteratorPtr {
 Prev moves the iterator one position backward and returns a pointer to the current symbol.
 It returns nil if it points before the first element of the list.
 */
-func (i *iteratorPtr) Prev() *symbol {
+func (i *iteratorPtr) Prev() *Symbol {
 	curStack := i.cur
 
 	i.pos--
@@ -309,7 +311,7 @@ func (i *iteratorPtr) Prev() *symbol {
 Cur returns a pointer to the current symbol.
 It returns nil if it points before the first element or after the last element of the list.
 */
-func (i *iteratorPtr) Cur() *symbol {
+func (i *iteratorPtr) Cur() *Symbol {
 	curStack := i.cur
 
 	if i.pos >= 0 && i.pos < curStack.Tos {
@@ -323,7 +325,7 @@ func (i *iteratorPtr) Cur() *symbol {
 Next moves the iterator one position forward and returns a pointer to the current symbol.
 It returns nil if it points after the last element of the list.
 */
-func (i *iteratorPtr) Next() *symbol {
+func (i *iteratorPtr) Next() *Symbol {
 	curStack := i.cur
 
 	i.pos++
@@ -0,0 +1,725 @@
+/*
+Package papageno is the runtime shared by the parsers generated by papageno.
+A generated package only contains the tables and the semantic functions of a language,
+described by a Lexer and a Grammar, and uses this package to lex and parse in parallel.
+*/
+package papageno
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"time"
+)
+
+/*
+ParsingStats contains some statistics about a parse.
+The remaining items of a pool are negative when the pool was too small, in which case it grows in the next parse.
+*/
+type ParsingStats struct {
+	NumLexThreads                           int
+	NumParseThreads                         int
+	StackPoolSizes                          []int
+	StackPoolNewNonterminalsSizes           []int
+	StackPtrPoolSizes                       []int
+	StackPoolSizeFinalPass                  int
+	StackPoolNewNonterminalsSizeFinalPass   int
+	StackPtrPoolSizeFinalPass               int
+	AllocMemTime                            time.Duration
+	CutPoints                               []int
+	LexTimes                                []time.Duration
+	LexTimeTotal                            time.Duration
+	NumTokens                               []int
+	NumTokensTotal                          int
+	ParseTimes                              []time.Duration
+	RecombiningStacksTime                   time.Duration
+	ParseTimeFinalPass                      time.Duration
+	ParseTimeTotal                          time.Duration
+	RemainingStacks                         []int
+	RemainingStacksNewNonterminals          []int
+	RemainingStackPtrs                      []int
+	RemainingStacksFinalPass                int
+	RemainingStacksNewNonterminalsFinalPass int
+	RemainingStackPtrsFinalPass             int
+}
+
+/*
+threadContext contains the data required by each thread, basically the thread number,
+an input list, a parsing stack and a list that contains all the generated nonterminals.
+*/
+type parseResult struct {
+	threadNum int
+	stack     *listOfStackPtrs
+	errs      []*ParseError
+}
+
+type lexResult struct {
+	threadNum int
+	tokenList *listOfStacks
+	errs      []*ParseError
+	condition int
+}
+
+/*
+threadJob is the parsing function executed in parallel by each thread.
+It takes as input a threadContext and a channel where it eventually sends the result.
+end is the offset of the end of the input, where the last thread places the # that delimits it.
+If the grammar has synchronizing terminals the thread recovers from the syntax errors and sends them
+together with its stack, otherwise it stops at the first one and sends a nil stack.
+*/
+func threadJob(g *Grammar, stats *ParsingStats, numThreads int, threadNum int, finalPass bool, input *listOfStacks, nextSym *Symbol, end int, stackPool *stackPool, stackPtrPool *stackPtrPool, c chan parseResult) {
+	start := time.Now()
+
+	phase := PhaseParsing
+	if finalPass {
+		phase = PhaseFinalPass
+	}
+
+	recovering := g.recovering()
+	errs := make([]*ParseError, 0)
+
+	inputIterator := input.HeadIterator()
+	newNonTerminalsList := newLos(stackPool)
+	stack := newLosPtr(stackPtrPool)
+	//tokensRead := 0
+
+	//If the thread is the first, push a # onto the stack
+	if threadNum == 0 {
+		stack.Push(&Symbol{Token: g.Term, Precedence: _NO_PREC})
+		//Otherwise, push the first token onto the stack
+	} else {
+		sym := inputIterator.Next()
+		sym.Precedence = _NO_PREC
+		stack.Push(sym)
+		//tokensRead++
+	}
+	//If the thread is the last, push a # onto the input list
+	var last *Symbol
+	if threadNum == numThreads-1 {
+		last = input.Push(&Symbol{Token: g.Term, Precedence: _NO_PREC, Offset: end})
+		//Otherwise, push onto the input list the first token of the next input list
+	} else {
+		last = input.Push(nextSym)
+	}
+
+	numYieldsPrec := 0
+
+	var pos int
+	var sym *Symbol
+	var ruleNum uint16
+	var lhs Token
+	var lhsSym *Symbol
+	var rhs []Token
+	var rhsSymbols []*Symbol
+	rhsBuf := make([]Token, g.MaxRHSLen)
+	rhsSymbolsBuf := make([]*Symbol, g.MaxRHSLen)
+
+	newNonTerm := &Symbol{Token: 0, Precedence: _NO_PREC}
+
+	//Get the first symbol from the input list
+	inputSym := inputIterator.Next()
+
+	//Iterate over all the input list
+	for inputSym != nil {
+		//stack.Println()
+
+		//If the current token is a nonterminal, push it onto the stack with no precedence relation
+		if !isTerminal(inputSym.Token) {
+			//fmt.Printf("Pushed (%s, %s)\n", TokenToString(inputSym.Token), precToString(NO_PREC))
+			inputSym.Precedence = _NO_PREC
+			stack.Push(inputSym)
+			inputSym = inputIterator.Next()
+			//tokensRead++
+			continue
+		}
+
+		//Find the first terminal on the stack and get the precedence between it and the current input token
+		firstTerminal := stack.FirstTerminal()
+		prec := g.getPrecedence(firstTerminal.Token, inputSym.Token)
+
+		switch prec {
+		//If it yields precedence, push the input token onto the stack with that precedence relation.
+		//Also increment the counter of the number of tokens yielding precedence.
+		case _YIELDS_PREC:
+			//fmt.Printf("Pushed (%s, %s)\n", TokenToString(inputSym.Token), precToString(prec))
+			inputSym.Precedence = _YIELDS_PREC
+			stack.Push(inputSym)
+			numYieldsPrec++
+			inputSym = inputIterator.Next()
+			//tokensRead++
+		//If it's equal in precedence, push the input token onto the stack with that precedence relation
+		case _EQ_PREC:
+			//fmt.Printf("Pushed (%s, %s)\n", TokenToString(inputSym.Token), precToString(prec))
+			inputSym.Precedence = _EQ_PREC
+			stack.Push(inputSym)
+			inputSym = inputIterator.Next()
+			//tokensRead++
+		//If it takes precedence, the next action depends on whether there are tokens that yield precedence onto the stack.
+		case _TAKES_PREC:
+			//If there are no tokens yielding precedence on the stack, push the input token onto the stack
+			//with take precedence as precedence relation
+			if numYieldsPrec == 0 {
+				//fmt.Printf("Pushed (%s, %s)\n", TokenToString(inputSym.Token), precToString(prec))
+				inputSym.Precedence = _TAKES_PREC
+				stack.Push(inputSym)
+				inputSym = inputIterator.Next()
+				//tokensRead++
+				//Otherwise, perform a reduction
+			} else {
+				pos = g.MaxRHSLen - 1
+
+				//Pop tokens from the stack until one that yields precedence is reached, saving them in rhsBuf.
+				//The scan stops when rhsBuf is full, since a longer handle cannot be the rhs of a rule
+				sym = stack.Pop()
+
+				for sym.Precedence != _YIELDS_PREC && pos > 0 {
+					rhsSymbolsBuf[pos] = sym
+					rhsBuf[pos] = sym.Token
+					sym = stack.Pop()
+					pos--
+				}
+				rhsSymbolsBuf[pos] = sym
+				rhsBuf[pos] = sym.Token
+
+				longHandle := sym.Precedence != _YIELDS_PREC
+
+				//Pop one last token, if it's a nonterminal add it to rhsBuf, otherwise ignore it (push it again onto the stack)
+				if !longHandle {
+					sym = stack.Pop()
+
+					if isTerminal(sym.Token) {
+						stack.Push(sym)
+					} else if pos > 0 {
+						pos--
+						rhsSymbolsBuf[pos] = sym
+						rhsBuf[pos] = sym.Token
+						stack.UpdateFirstTerminal()
+					} else {
+						stack.Push(sym)
+						longHandle = true
+					}
+				}
+
+				//If the handle is longer than any rhs, abort the parsing or replace it with an error node.
+				//This may happen after recovering, when an error node is pushed after a terminal
+				if longHandle {
+					rhsSymbols = popLongHandle(&stack, rhsSymbolsBuf)
+
+					errs = append(errs, g.noMatchError(phase, firstTerminal, inputSym, symbolTokens(rhsSymbols)))
+
+					if !recovering {
+						c <- parseResult{threadNum, nil, errs}
+						return
+					}
+
+					stack.Push(pushErrorNode(&newNonTerminalsList, rhsSymbols))
+					numYieldsPrec--
+					continue
+				}
+
+				//Obtain the actual rhs from the buffers
+				rhsSymbols = rhsSymbolsBuf[pos:]
+				rhs = rhsBuf[pos:]
+
+				//When recovering, the rhs containing an error node is replaced by another error node
+				//without reporting it again, since its error has already been reported
+				if recovering && containsErrors(rhs) {
+					stack.Push(pushErrorNode(&newNonTerminalsList, rhsSymbols))
+					numYieldsPrec--
+					continue
+				}
+
+				//Find corresponding lhs and ruleNum
+				lhs, ruleNum = g.findMatch(rhs)
+
+				//If a rule with that rhs does not exist, abort the parsing or replace the rhs with an error node
+				if lhs == g.Empty {
+					/*fmt.Print("Error, could not find a reduction for ")
+					for i := 0; i < len(rhs); i++ {
+						fmt.Print(TokenToString(rhs[i]))
+						fmt.Print(",")
+					}
+					fmt.Println()*/
+
+					errs = append(errs, g.noMatchError(phase, firstTerminal, inputSym, rhs))
+
+					if !recovering {
+						c <- parseResult{threadNum, nil, errs}
+						return
+					}
+
+					stack.Push(pushErrorNode(&newNonTerminalsList, rhsSymbols))
+					numYieldsPrec--
+					continue
+				}
+
+				/*fmt.Print("Reduced ")
+				for i := 0; i < len(rhs); i++ {
+					fmt.Print(TokenToString(rhs[i]))
+					fmt.Print(",")
+				}
+				fmt.Print(" -> ")
+				fmt.Println(TokenToString(lhs))*/
+
+				//Push the new nonterminal onto the appropriate list to save it, spanning its rhs
+				newNonTerm.Token = lhs
+				newNonTerm.Offset = rhsSymbols[0].Offset
+				newNonTerm.Length = rhsSymbols[len(rhsSymbols)-1].Offset + rhsSymbols[len(rhsSymbols)-1].Length - newNonTerm.Offset
+				lhsSym = newNonTerminalsList.Push(newNonTerm)
+
+				//Execute the semantic action
+				g.Function(threadNum, ruleNum, lhsSym, rhsSymbols)
+
+				//Push the new nonterminal onto the stack
+				stack.Push(lhsSym)
+
+				//Decrement the counter of the number of tokens yielding precedence
+				numYieldsPrec--
+			}
+		//If there's no precedence relation, abort the parsing or skip to a synchronizing terminal
+		case _NO_PREC:
+			//fmt.Printf("Error, no precedence relation between %s and %s\n", TokenToString(firstTerminal.Token), TokenToString(inputSym.Token))
+
+			errs = append(errs, g.noPrecError(phase, firstTerminal, inputSym))
+
+			if !recovering {
+				c <- parseResult{threadNum, nil, errs}
+				return
+			}
+
+			inputSym = g.recoverFromError(&stack, &inputIterator, inputSym, last, &numYieldsPrec, &newNonTerminalsList)
+		}
+	}
+
+	if !finalPass {
+		stats.ParseTimes[threadNum] = time.Since(start)
+	} else {
+		stats.ParseTimeFinalPass = time.Since(start)
+	}
+
+	c <- parseResult{threadNum, &stack, errs}
+}
+
+/*
+Parser parses in parallel using a Lexer and an operator precedence Grammar.
+It owns the memory pools, the statistics and the configuration of its parses, so different Parsers may be used
+at the same time by different goroutines, while a Parser must not be used by more than one goroutine at a time.
+The pools are kept between the parses and only grow when an input needs larger ones,
+so the tree returned by a parse is valid until the next parse, Reset or Release.
+Lexer may be nil if only ParseTokens is used.
+CPUProfileFile, if not nil, is the file where a CPU profile of the parsing phase is written.
+If ForceGC is true the garbage collector is run after allocating the memory of each parse,
+so that it does not run concurrently with the parser.
+*/
+type Parser struct {
+	Lexer          *Lexer
+	Grammar        *Grammar
+	Stats          ParsingStats
+	CPUProfileFile *os.File
+	ForceGC        bool
+
+	pools *pools
+}
+
+/*
+Reset clears the memory pools, so that they do not reference the tree of the last parse
+and its values anymore, while keeping them for the next parse.
+*/
+func (p *Parser) Reset() {
+	if p.pools != nil {
+		p.pools.clear()
+	}
+}
+
+/*
+Release drops the memory pools, so that they can be collected by the garbage collector.
+The next parse allocates them again.
+*/
+func (p *Parser) Release() {
+	p.pools = nil
+}
+
+/*
+pools contains the memory preallocated for the lexing and the parsing threads.
+*/
+type pools struct {
+	stackPools                        []*stackPool
+	stackPoolsNewNonterminals         []*stackPool
+	stackPtrPools                     []*stackPtrPool
+	stackPoolFinalPass                *stackPool
+	stackPoolNewNonterminalsFinalPass *stackPool
+	stackPtrPoolFinalPass             *stackPtrPool
+}
+
+/*
+preparePools returns the pools required to parse an input of numTokens tokens (estimated) using numThreads threads.
+The pools of the previous parse are rewound and reused when they are large enough, otherwise larger ones are allocated.
+The sizes of the pools are saved in Stats.
+*/
+func (p *Parser) preparePools(numTokens float64, numThreads int) *pools {
+	stats := &p.Stats
+
+	//The last multiplication by  is to account for the generated nonterminals
+	stackPoolBaseSize := math.Ceil(((numTokens / float64(_STACK_SIZE)) / float64(numThreads)))
+	stackPtrPoolBaseSize := math.Ceil((numTokens / float64(_STACK_PTR_SIZE)) / float64(numThreads))
+
+	if p.pools == nil {
+		p.pools = &pools{}
+	}
+	pools := p.pools
+
+	for len(pools.stackPools) < numThreads {
+		pools.stackPools = append(pools.stackPools, nil)
+		pools.stackPoolsNewNonterminals = append(pools.stackPoolsNewNonterminals, nil)
+		pools.stackPtrPools = append(pools.stackPtrPools, nil)
+	}
+
+	stats.StackPoolSizes = make([]int, numThreads)
+	stats.StackPoolNewNonterminalsSizes = make([]int, numThreads)
+	stats.StackPtrPoolSizes = make([]int, numThreads)
+
+	for i := 0; i < numThreads; i++ {
+		pools.stackPools[i] = reuseStackPool(pools.stackPools[i], int(math.Ceil(stackPoolBaseSize*1.2)))
+		stats.StackPoolSizes[i] = len(pools.stackPools[i].pool)
+		pools.stackPoolsNewNonterminals[i] = reuseStackPool(pools.stackPoolsNewNonterminals[i], int(math.Ceil(stackPoolBaseSize*0.8)))
+		stats.StackPoolNewNonterminalsSizes[i] = len(pools.stackPoolsNewNonterminals[i].pool)
+		pools.stackPtrPools[i] = reuseStackPtrPool(pools.stackPtrPools[i], int(stackPtrPoolBaseSize))
+		stats.StackPtrPoolSizes[i] = len(pools.stackPtrPools[i].pool)
+	}
+
+	if numThreads > 1 {
+		pools.stackPoolFinalPass = reuseStackPool(pools.stackPoolFinalPass, int(math.Ceil(stackPoolBaseSize*0.1*float64(numThreads))))
+		stats.StackPoolSizeFinalPass = len(pools.stackPoolFinalPass.pool)
+		pools.stackPoolNewNonterminalsFinalPass = reuseStackPool(pools.stackPoolNewNonterminalsFinalPass, int(math.Ceil(stackPoolBaseSize*0.05*float64(numThreads))))
+		stats.StackPoolNewNonterminalsSizeFinalPass = len(pools.stackPoolNewNonterminalsFinalPass.pool)
+		pools.stackPtrPoolFinalPass = reuseStackPtrPool(pools.stackPtrPoolFinalPass, int(math.Ceil(stackPtrPoolBaseSize*0.1)))
+		stats.StackPtrPoolSizeFinalPass = len(pools.stackPtrPoolFinalPass.pool)
+	}
+
+	return pools
+}
+
+/*
+clear clears every pool, as done by Reset.
+*/
+func (p *pools) clear() {
+	for i := range p.stackPools {
+		p.stackPools[i].Clear()
+		p.stackPoolsNewNonterminals[i].Clear()
+		p.stackPtrPools[i].Clear()
+	}
+
+	if p.stackPoolFinalPass != nil {
+		p.stackPoolFinalPass.Clear()
+		p.stackPoolNewNonterminalsFinalPass.Clear()
+		p.stackPtrPoolFinalPass.Clear()
+	}
+}
+
+/*
+ParseString parses a string in parallel.
+It takes as input a string as a slice of bytes and the number of threads, and returns
+the symbol at the root of the syntactic tree, or an error if the string could not be parsed.
+If the grammar has synchronizing terminals the parser recovers from the errors: it returns ParseErrors
+together with a tree where the symbols that could not be parsed are replaced by error nodes,
+whose token is ErrorToken, while the bytes that could not be lexed are skipped.
+The statistics about the parse are saved in Stats.
+*/
+func (p *Parser) ParseString(str []byte, numThreads int) (*Symbol, error) {
+	l := p.Lexer
+	g := p.Grammar
+
+	if l == nil {
+		return nil, errors.New("the parser has no lexer, use ParseTokens")
+	}
+
+	p.Stats = ParsingStats{}
+	stats := &p.Stats
+
+	rawInputSize := len(str)
+
+	avgCharsPerToken := float64(12.5)
+
+	//Alloc memory required by both the lexer and the parser, or reuse the one of the previous parse.
+	//The call to runtime.GC() avoids the garbage collector to run concurrently with the parser
+	start := time.Now()
+
+	pools := p.preparePools(float64(rawInputSize)/avgCharsPerToken, numThreads)
+
+	if l.PreallocMem != nil {
+		l.PreallocMem(rawInputSize, numThreads)
+	}
+
+	if g.PreallocMem != nil {
+		g.PreallocMem(rawInputSize, numThreads)
+	}
+
+	if p.ForceGC {
+		runtime.GC()
+	}
+
+	stats.AllocMemTime = time.Since(start)
+
+	//Lex the file to obtain the input list
+	start = time.Now()
+
+	cutPoints, numLexThreads := findCutPoints(&l.CutPointsAutomaton, str, numThreads)
+
+	stats.NumLexThreads = numLexThreads
+	stats.LexTimes = make([]time.Duration, numLexThreads)
+	stats.CutPoints = cutPoints
+
+	if numLexThreads < numThreads {
+		fmt.Printf("It was not possible to find cut points for all %d threads.\n", numThreads)
+		fmt.Printf("The number of lexing threads was reduced to %d.\n", numLexThreads)
+	}
+
+	lexC := make(chan lexResult)
+
+	for i := 0; i < numLexThreads; i++ {
+		go lex(l, stats, i, str[cutPoints[i]:cutPoints[i+1]], cutPoints[i], 0, g.recovering(), pools.stackPools[i], lexC)
+	}
+
+	lexResults := make([]lexResult, numLexThreads)
+
+	for i := 0; i < numLexThreads; i++ {
+		curLexResult := <-lexC
+		lexResults[curLexResult.threadNum] = curLexResult
+	}
+
+	//Each thread starts lexing in the first start condition, so if the previous thread ended in another one,
+	//for example in the middle of a comment, the part of the input of the thread is lexed again from that condition
+	for i := 1; i < numLexThreads; i++ {
+		if condition := lexResults[i-1].condition; condition != 0 {
+			pools.stackPools[i].Rewind()
+			go lex(l, stats, i, str[cutPoints[i]:cutPoints[i+1]], cutPoints[i], condition, g.recovering(), pools.stackPools[i], lexC)
+			lexResults[i] = <-lexC
+		}
+	}
+
+	stats.LexTimeTotal = time.Since(start)
+
+	errs := make([]*ParseError, 0)
+	for i := 0; i < numLexThreads; i++ {
+		errs = append(errs, lexResults[i].errs...)
+	}
+
+	//If lexing fails, abort the parsing, reporting the error of the first thread that failed
+	if len(errs) > 0 && !g.recovering() {
+		return nil, newParseError(errs, false, str)
+	}
+
+	input := lexResults[0].tokenList
+
+	for i := 1; i < numLexThreads; i++ {
+		input.Merge(*lexResults[i].tokenList)
+	}
+
+	result, parseErrs := p.parse(input, len(str), numThreads, pools)
+
+	return result, newParseError(append(errs, parseErrs...), g.recovering(), str)
+}
+
+/*
+ParseTokens parses in parallel a list of symbols that were already lexed.
+It allows to use a parser with a hand-written lexer.
+It returns the symbol at the root of the syntactic tree, or an error if the symbols could not be parsed.
+The errors are located by the Offset of the symbols, without lines and columns,
+and are recovered from as in ParseString.
+The statistics about the parse are saved in Stats.
+*/
+func (p *Parser) ParseTokens(tokens []Symbol, numThreads int) (*Symbol, error) {
+	g := p.Grammar
+
+	p.Stats = ParsingStats{}
+	stats := &p.Stats
+
+	start := time.Now()
+
+	pools := p.preparePools(float64(len(tokens)), numThreads)
+
+	if g.PreallocMem != nil {
+		g.PreallocMem(len(tokens), numThreads)
+	}
+
+	if p.ForceGC {
+		runtime.GC()
+	}
+
+	stats.AllocMemTime = time.Since(start)
+
+	start = time.Now()
+
+	stats.NumLexThreads = 1
+	stats.CutPoints = []int{0, len(tokens)}
+
+	input := newLos(pools.stackPools[0])
+
+	for i := range tokens {
+		input.Push(&tokens[i])
+	}
+
+	stats.LexTimes = []time.Duration{time.Since(start)}
+	stats.LexTimeTotal = stats.LexTimes[0]
+
+	end := 0
+	if len(tokens) > 0 {
+		end = tokens[len(tokens)-1].Offset + tokens[len(tokens)-1].Length
+	}
+
+	result, errs := p.parse(&input, end, numThreads, pools)
+
+	return result, newParseError(errs, g.recovering(), nil)
+}
+
+/*
+parse runs the parsing threads on the input list and, if more than one thread is used,
+the final pass that combines their results. end is the offset of the end of the input.
+It returns the errors of the threads, in order, followed by the ones of the final pass.
+*/
+func (p *Parser) parse(input *listOfStacks, end int, numThreads int, pools *pools) (*Symbol, []*ParseError) {
+	g := p.Grammar
+	stats := &p.Stats
+
+	if p.CPUProfileFile != nil {
+		if err := pprof.StartCPUProfile(p.CPUProfileFile); err != nil {
+			log.Fatal("could not start CPU profile: ", err)
+		}
+		defer pprof.StopCPUProfile()
+	}
+
+	stats.NumTokensTotal = input.Length()
+
+	if input.Length() == 0 {
+		return nil, nil
+	}
+
+	start := time.Now()
+
+	var result *Symbol = nil
+
+	//If there are not enough stacks in the input, reduce the number of threads.
+	//This is because the input is split by splitting stacks, not stack contents.
+	if input.NumStacks() < numThreads {
+		fmt.Println("There are less stacks than threads, reducing the number of threads to", input.NumStacks())
+		numThreads = input.NumStacks()
+	}
+
+	stats.NumParseThreads = numThreads
+	stats.ParseTimes = make([]time.Duration, numThreads)
+
+	//Split the input list
+	inputLists := input.Split(numThreads)
+
+	stats.NumTokens = make([]int, numThreads)
+	for i := 0; i < numThreads; i++ {
+		stats.NumTokens[i] = inputLists[i].Length()
+	}
+
+	parseResults := make([]parseResult, numThreads)
+
+	c := make(chan parseResult)
+
+	//Run the threads
+	for i := 0; i < numThreads; i++ {
+		var nextSym *Symbol = nil
+
+		//The first symbol of the next input list is copied before the next thread starts,
+		//since that thread changes its precedence
+		if i < numThreads-1 {
+			nextInputListIter := inputLists[i+1].HeadIterator()
+			firstSym := *nextInputListIter.Next()
+			nextSym = &firstSym
+		}
+
+		go threadJob(g, stats, numThreads, i, false, &inputLists[i], nextSym, end, pools.stackPoolsNewNonterminals[i], pools.stackPtrPools[i], c)
+	}
+
+	//Wait for each thread to finish its job
+	for i := 0; i < numThreads; i++ {
+		curParseResults := <-c
+
+		parseResults[curParseResults.threadNum] = curParseResults
+	}
+
+	//If one of the threads fails, abort the parsing, reporting the error of the first thread that failed
+	errs := make([]*ParseError, 0)
+	for i := 0; i < numThreads; i++ {
+		if parseResults[i].stack == nil {
+			stats.ParseTimeTotal = time.Since(start)
+			return nil, parseResults[i].errs
+		}
+		errs = append(errs, parseResults[i].errs...)
+	}
+
+	//If the number of threads is greater than one, a final pass is required
+	if numThreads > 1 {
+		startRecombiningStacks := time.Now()
+		//Create the final input by joining together the stacks from the previous step
+		finalPassInput := newLos(pools.stackPoolFinalPass)
+		for i := 0; i < numThreads; i++ {
+			iterator := parseResults[i].stack.HeadIterator()
+			//Ignore the first token
+			iterator.Next()
+			sym := iterator.Next()
+			for sym != nil {
+				finalPassInput.Push(sym)
+				sym = iterator.Next()
+			}
+		}
+		stats.RecombiningStacksTime = time.Since(startRecombiningStacks)
+
+		go threadJob(g, stats, 1, 0, true, &finalPassInput, nil, end, pools.stackPoolNewNonterminalsFinalPass, pools.stackPtrPoolFinalPass, c)
+
+		finalPassParseResult := <-c
+
+		errs = append(errs, finalPassParseResult.errs...)
+
+		if finalPassParseResult.stack == nil {
+			stats.ParseTimeTotal = time.Since(start)
+			return nil, errs
+		}
+
+		//Pop tokens from the stack until a nonterminal is found
+		sym := finalPassParseResult.stack.Pop()
+
+		for isTerminal(sym.Token) {
+			sym = finalPassParseResult.stack.Pop()
+		}
+
+		//Set the result as the nonterminal symbol
+		result = sym
+
+		stats.RemainingStacksFinalPass = pools.stackPoolFinalPass.Remainder()
+		stats.RemainingStacksNewNonterminalsFinalPass = pools.stackPoolNewNonterminalsFinalPass.Remainder()
+		stats.RemainingStackPtrsFinalPass = pools.stackPtrPoolFinalPass.Remainder()
+	} else {
+		//Pop tokens from the stack until a nonterminal is found
+		sym := parseResults[0].stack.Pop()
+
+		for isTerminal(sym.Token) {
+			sym = parseResults[0].stack.Pop()
+		}
+
+		//Set the result as the nonterminal symbol
+		result = sym
+	}
+
+	stats.RemainingStacks = make([]int, numThreads)
+	stats.RemainingStacksNewNonterminals = make([]int, numThreads)
+	stats.RemainingStackPtrs = make([]int, numThreads)
+
+	for i := 0; i < numThreads; i++ {
+		stats.RemainingStacks[i] = pools.stackPools[i].Remainder()
+		stats.RemainingStacksNewNonterminals[i] = pools.stackPoolsNewNonterminals[i].Remainder()
+		stats.RemainingStackPtrs[i] = pools.stackPtrPools[i].Remainder()
+	}
+
+	stats.ParseTimeTotal = time.Since(start)
+
+	return result, errs
+}
@@ -0,0 +1,37 @@
+package papageno
+
+import (
+	"sort"
+)
+
+/*
+LineIndex maps the byte offsets of an input, such as the Offset of a Symbol, to lines and columns.
+*/
+type LineIndex struct {
+	//lineStarts contains the offset of the first byte of each line
+	lineStarts []int
+}
+
+/*
+NewLineIndex creates the LineIndex of an input, whose lines are terminated by '\n'.
+*/
+func NewLineIndex(data []byte) *LineIndex {
+	lineStarts := []int{0}
+	for i, c := range data {
+		if c == '\n' {
+			lineStarts = append(lineStarts, i+1)
+		}
+	}
+	return &LineIndex{lineStarts}
+}
+
+/*
+Position returns the line and the column of the byte at offset, both starting from 1.
+The column counts bytes, so a multi-byte character advances it by more than one.
+*/
+func (index *LineIndex) Position(offset int) (line int, column int) {
+	line = sort.Search(len(index.lineStarts), func(i int) bool {
+		return index.lineStarts[i] > offset
+	})
+	return line, offset - index.lineStarts[line-1] + 1
+}
@@ -1,4 +1,4 @@
-package xml
+package papageno
 
 const (
 	_YIELDS_PREC = iota
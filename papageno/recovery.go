@@ -0,0 +1,180 @@
+package papageno
+
+/*
+ErrorToken is the token of the error nodes, the nonterminals that replace the symbols
+that could not be parsed when the parser recovers from the syntax errors.
+The children of an error node are the symbols it replaces.
+*/
+const ErrorToken Token = 0x7FFF
+
+/*
+recovering returns true if the parser recovers from the syntax errors,
+which happens when the grammar declares at least one synchronizing terminal.
+*/
+func (g *Grammar) recovering() bool {
+	return len(g.SyncTerminals) > 0
+}
+
+/*
+isSync returns true if token is a synchronizing terminal.
+*/
+func (g *Grammar) isSync(token Token) bool {
+	for _, syncTerminal := range g.SyncTerminals {
+		if token == syncTerminal {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+containsErrors returns true if rhs contains an error node.
+*/
+func containsErrors(rhs []Token) bool {
+	for _, token := range rhs {
+		if token == ErrorToken {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+pushErrorNode saves in list an error node whose children are the discarded symbols and returns it.
+*/
+func pushErrorNode(list *listOfStacks, discarded []*Symbol) *Symbol {
+	node := list.Push(&Symbol{Token: ErrorToken, Precedence: _NO_PREC})
+
+	node.Child = discarded[0]
+	for i := 0; i < len(discarded)-1; i++ {
+		discarded[i].Next = discarded[i+1]
+	}
+	discarded[len(discarded)-1].Next = nil
+
+	last := discarded[len(discarded)-1]
+	node.Offset = discarded[0].Offset
+	node.Length = last.Offset + last.Length - node.Offset
+
+	return node
+}
+
+/*
+popLongHandle pops from the stack the rest of a handle longer than MaxRHSLen, whose last MaxRHSLen symbols
+were already popped into buf, and returns all its symbols, including the nonterminal that precedes it, if any.
+*/
+func popLongHandle(stack *listOfStackPtrs, buf []*Symbol) []*Symbol {
+	//popped contains the symbols popped from the stack, starting from the top
+	popped := make([]*Symbol, 0)
+
+	sym := buf[0]
+	for sym.Precedence != _YIELDS_PREC {
+		sym = stack.Pop()
+		popped = append(popped, sym)
+	}
+
+	sym = stack.Pop()
+	if isTerminal(sym.Token) {
+		stack.Push(sym)
+	} else {
+		popped = append(popped, sym)
+		stack.UpdateFirstTerminal()
+	}
+
+	handle := make([]*Symbol, 0, len(popped)+len(buf))
+	for i := len(popped) - 1; i >= 0; i-- {
+		handle = append(handle, popped[i])
+	}
+
+	return append(handle, buf...)
+}
+
+/*
+symbolTokens returns the tokens of symbols.
+*/
+func symbolTokens(symbols []*Symbol) []Token {
+	tokens := make([]Token, len(symbols))
+	for i, sym := range symbols {
+		tokens[i] = sym.Token
+	}
+	return tokens
+}
+
+/*
+popSymbol pops a symbol from the stack of a parsing thread, keeping up to date
+its first terminal and the number of terminals yielding precedence.
+*/
+func popSymbol(stack *listOfStackPtrs, numYieldsPrec *int) *Symbol {
+	sym := stack.Pop()
+
+	if sym.Precedence == _YIELDS_PREC {
+		*numYieldsPrec--
+	}
+	if isTerminal(sym.Token) {
+		stack.UpdateFirstTerminal()
+	}
+
+	return sym
+}
+
+/*
+recoverFromError is called by a parsing thread when there is no precedence relation between the terminal
+on top of the stack and inputSym. It skips the input up to a synchronizing terminal, or up to last,
+the last symbol of the input of the thread, and pops the stack until the terminal on top of it has a precedence
+relation with that terminal. The first symbol of the stack is never popped and last is never skipped,
+since they are needed to combine the stacks of the threads in the final pass.
+The discarded symbols are replaced by an error node on the stack, and the symbol where the parsing resumes is returned.
+*/
+func (g *Grammar) recoverFromError(stack *listOfStackPtrs, inputIterator *iterator, inputSym *Symbol, last *Symbol, numYieldsPrec *int, nonterminals *listOfStacks) *Symbol {
+	skipped := make([]*Symbol, 0)
+	//popped contains the symbols popped from the stack, starting from the top
+	popped := make([]*Symbol, 0)
+
+	for {
+		for inputSym != last && !g.isSync(inputSym.Token) {
+			skipped = append(skipped, inputSym)
+			inputSym = inputIterator.Next()
+		}
+
+		for stack.Length() > 1 && g.getPrecedence(stack.FirstTerminal().Token, inputSym.Token) == _NO_PREC {
+			popped = append(popped, popSymbol(stack, numYieldsPrec))
+		}
+
+		if inputSym == last || g.getPrecedence(stack.FirstTerminal().Token, inputSym.Token) != _NO_PREC {
+			break
+		}
+
+		//Not even the first symbol of the stack has a relation with the synchronizing terminal, so it is skipped too
+		skipped = append(skipped, inputSym)
+		inputSym = inputIterator.Next()
+	}
+
+	if len(popped) > 0 || len(skipped) > 0 {
+		//A nonterminal on top of the stack is replaced as well, since it cannot be followed by the error node
+		if stack.Length() > 1 {
+			top := stack.Pop()
+			if isTerminal(top.Token) {
+				stack.Push(top)
+			} else {
+				popped = append(popped, top)
+			}
+		}
+
+		discarded := make([]*Symbol, 0, len(popped)+len(skipped))
+		for i := len(popped) - 1; i >= 0; i-- {
+			discarded = append(discarded, popped[i])
+		}
+		discarded = append(discarded, skipped...)
+
+		stack.Push(pushErrorNode(nonterminals, discarded))
+	}
+
+	//Only the first symbol of a thread other than the first may have no relation with last,
+	//which is pushed anyway and combined with the other stacks in the final pass
+	if g.getPrecedence(stack.FirstTerminal().Token, inputSym.Token) == _NO_PREC {
+		inputSym.Precedence = _TAKES_PREC
+		stack.Push(inputSym)
+		return inputIterator.Next()
+	}
+
+	return inputSym
+}
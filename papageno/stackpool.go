@@ -0,0 +1,102 @@
+package papageno
+
+/*
+stackPool allows to preallocate elements with type stack.
+It is thread-safe.
+*/
+type stackPool struct {
+	pool    []stack
+	cur     int
+	missing int
+}
+
+/*
+newStackPool creates a new pool, allocating memory for a number of elements equal to length.
+*/
+func newStackPool(length int) *stackPool {
+	p := stackPool{make([]stack, length), 0, 0}
+
+	return &p
+}
+
+/*
+Get gets an item from the pool if available, otherwise it initializes a new one.
+It is NOT thread-safe.
+*/
+func (p *stackPool) Get() *stack {
+	if p.cur >= len(p.pool) {
+		//The items given out past the end of the pool are counted too, so that it grows when it is reused
+		p.cur++
+		return new(stack)
+	}
+	addr := &p.pool[p.cur]
+	p.cur++
+	return addr
+}
+
+/*
+Remainder returns the number of items remaining in the pool.
+It is negative if the pool was too small and new items were allocated.
+*/
+func (p *stackPool) Remainder() int {
+	return len(p.pool) - p.cur
+}
+
+/*
+Rewind makes the items given out by the pool available again, emptying them.
+The content of the items is not cleared, since it is overwritten when they are used again.
+*/
+func (p *stackPool) Rewind() {
+	used := p.used()
+	for i := 0; i < used; i++ {
+		p.pool[i].Tos = 0
+		p.pool[i].Prev = nil
+		p.pool[i].Next = nil
+	}
+	p.cur = 0
+}
+
+/*
+Clear zeroes the items given out by the pool, so that they do not reference the symbols
+of the last parse anymore, and makes them available again.
+*/
+func (p *stackPool) Clear() {
+	used := p.used()
+	for i := 0; i < used; i++ {
+		p.pool[i] = stack{}
+	}
+	p.cur = 0
+}
+
+/*
+used returns the number of items of the pool given out since it was last rewound,
+saving the largest number of items that were missing from it.
+*/
+func (p *stackPool) used() int {
+	if p.cur > len(p.pool) {
+		if p.cur-len(p.pool) > p.missing {
+			p.missing = p.cur - len(p.pool)
+		}
+		return len(p.pool)
+	}
+	return p.cur
+}
+
+/*
+reuseStackPool rewinds pool and returns it if it contains at least length items,
+otherwise it returns a new pool with length items.
+A pool that was too small in a previous parse is replaced by one that also contains the items that were missing.
+*/
+func reuseStackPool(pool *stackPool, length int) *stackPool {
+	if pool == nil {
+		return newStackPool(length)
+	}
+	pool.Rewind()
+	if len(pool.pool)+pool.missing > length {
+		length = len(pool.pool) + pool.missing
+	}
+	if len(pool.pool) < length {
+		return newStackPool(length)
+	}
+	return pool
+}
@@ -1,6 +1,6 @@
-package xml
+package papageno
 
-//This is approx. 1MB per stack (on 64 bit architecture)
+// This is approx. 1MB per stack (on 64 bit architecture)
 const _STACK_PTR_SIZE int = 131000
 
 /*
@@ -8,7 +8,7 @@ stackPtr contains a fixed size array of symbol pointers, the current position in
 and pointers to the previous and next stacks.
 */
 type stackPtr struct {
-	Data [_STACK_PTR_SIZE]*symbol
+	Data [_STACK_PTR_SIZE]*Symbol
 	Tos  int
 	Prev *stackPtr
 	Next *stackPtr
@@ -0,0 +1,65 @@
+package papageno
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+/*
+Token identifies a terminal or a nonterminal of a grammar.
+Nonterminals are numbered starting from 0, while terminals are numbered starting from 0x8000.
+*/
+type Token uint16
+
+/*
+IsTerminal returns true if the token is a terminal of the grammar, false if it is a nonterminal.
+*/
+func (token Token) IsTerminal() bool {
+	return isTerminal(token)
+}
+
+func isTerminal(token Token) bool {
+	return token >= 0x8000
+}
+
+/*
+tokenValue returns the index of a token among the terminals or the nonterminals of the grammar.
+*/
+func tokenValue(token Token) uint16 {
+	return 0x7FFF & uint16(token)
+}
+
+/*
+Symbol contains a token and its value, a precedence and pointers to build the syntactic tree.
+The children of a nonterminal are reachable through Child and then through the Next pointer of each child.
+Offset and Length locate the symbol in the input, in bytes: a terminal spans the text it was lexed from
+and a nonterminal spans the symbols it was reduced from.
+*/
+type Symbol struct {
+	Token      Token
+	Precedence uint16
+	Value      interface{}
+	Next       *Symbol
+	Child      *Symbol
+	Offset     int
+	Length     int
+}
+
+func (s *Symbol) printTreeR(w io.Writer, tokenToString func(Token) string, level int) {
+	if s == nil {
+		return
+	}
+
+	fmt.Fprintf(w, ">  %s%s\n", strings.Repeat("  ", level), tokenToString(s.Token))
+	s.Child.printTreeR(w, tokenToString, level+1)
+	s.Next.printTreeR(w, tokenToString, level)
+}
+
+/*
+PrintTree writes to w the syntactic tree using the symbol as the root.
+The tokens are printed using tokenToString, which is usually the TokenString function of a generated package.
+*/
+func (root *Symbol) PrintTree(w io.Writer, tokenToString func(Token) string) {
+	root.printTreeR(w, tokenToString, 0)
+}